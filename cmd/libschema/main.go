@@ -30,18 +30,7 @@ func Convert(htmlInput *C.char, elementsToStrip **C.char, elementsLen C.int) *C.
 
 	// Convert C string to Go string
 	goHTML := C.GoString(htmlInput)
-	var goElementsToStrip []string
-
-	// Convert C array to Go slice using pointer arithmetic
-	if elementsToStrip != nil && elementsLen > 0 {
-		// Create a slice from the C array
-		cArray := unsafe.Slice(elementsToStrip, elementsLen)
-		for _, cstr := range cArray {
-			if cstr != nil {
-				goElementsToStrip = append(goElementsToStrip, C.GoString(cstr))
-			}
-		}
-	}
+	goElementsToStrip := goStrings(elementsToStrip, elementsLen)
 
 	// Use the converter package to process HTML with options
 	// Convert C ints to Go bools
@@ -61,6 +50,45 @@ func Convert(htmlInput *C.char, elementsToStrip **C.char, elementsLen C.int) *C.
 	return C.CString(md)
 }
 
+// ConvertJSON converts HTML to the document as versioned JSON (see
+// converter.JSONDocument). The raw HTML is converted directly: ProcessHTML
+// would turn images into text before they could become image blocks, and
+// HTMLToJSON strips the same elements itself. It returns an empty string when
+// the HTML cannot be converted.
+//
+//export ConvertJSON
+func ConvertJSON(htmlInput *C.char, elementsToStrip **C.char, elementsLen C.int) *C.char {
+	if htmlInput == nil {
+		return C.CString("")
+	}
+
+	stripConfig := converter.StripConfig{
+		ElementsToStrip: goStrings(elementsToStrip, elementsLen),
+	}
+	out, err := converter.HTMLToJSON([]byte(C.GoString(htmlInput)), stripConfig)
+	if err != nil {
+		return C.CString("")
+	}
+
+	return C.CString(string(out))
+}
+
+// goStrings converts a C array of strings to a Go slice using pointer
+// arithmetic, skipping NULL entries
+func goStrings(arr **C.char, n C.int) []string {
+	if arr == nil || n <= 0 {
+		return nil
+	}
+	var strs []string
+	// Create a slice from the C array
+	for _, cstr := range unsafe.Slice(arr, n) {
+		if cstr != nil {
+			strs = append(strs, C.GoString(cstr))
+		}
+	}
+	return strs
+}
+
 //export Free
 func Free(str *C.char) {
 	C.free(unsafe.Pointer(str))
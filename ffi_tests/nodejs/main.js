@@ -6,6 +6,7 @@ const lib = koffi.load('./build/libschema.so')
 // Define function signatures - using char* for auto string conversion
 // Second param is char** (array of strings), third is int (array length)
 const Convert = lib.func('char* Convert(char* htmlInput, char** elementsToStrip, int elementsLen)')
+const ConvertJSON = lib.func('char* ConvertJSON(char* htmlInput, char** elementsToStrip, int elementsLen)')
 
 // Note: Not using Free() due to koffi memory management complexity
 // In production, you'd need a proper memory management strategy
@@ -17,7 +18,7 @@ const htmlInput = `<!DOCTYPE html>
 <body>
     <header><h1>This should be stripped</h1></header>
     <nav><a href="/">Home</a></nav>
-    <main><p>This content should remain</p></main>
+    <main><p>This content should remain</p><img src="chart.png" alt="Chart"></main>
     <footer><p>This should also be stripped</p></footer>
 </body>
 </html>`
@@ -57,6 +58,31 @@ if (result.includes('This content should remain')) {
     errors++;
 }
 
+console.log('\nTesting ConvertJSON()...')
+const doc = JSON.parse(ConvertJSON(htmlInput, elementsToStrip, elementsToStrip.length))
+console.log(JSON.stringify(doc, null, 2))
+
+if (doc.version === 1 && doc.metadata.title === 'Test') {
+    console.log('\n✓ Versioned JSON with metadata')
+} else {
+    console.log('\n✗ Missing version or metadata')
+    errors++;
+}
+
+if (doc.blocks.some(b => b.type === 'paragraph' && b.text === 'This content should remain')) {
+    console.log('✓ Main content preserved as a paragraph block')
+} else {
+    console.log('✗ Main content block missing')
+    errors++;
+}
+
+if (doc.blocks.some(b => b.type === 'image' && b.alt === 'Chart' && b.src === 'chart.png')) {
+    console.log('✓ Image kept as an image block')
+} else {
+    console.log('✗ Image block missing')
+    errors++;
+}
+
 if (errors > 0) {
     console.log(`\n${errors} errors found`)
     process.exit(1)
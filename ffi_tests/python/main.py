@@ -4,6 +4,7 @@ Test script to verify the CGO library works via Python FFI
 """
 
 from ctypes import cdll, c_char_p, POINTER
+import json
 import os
 
 # Load the shared library
@@ -14,6 +15,8 @@ lib = cdll.LoadLibrary(lib_path)
 from ctypes import c_int
 lib.Convert.argtypes = [c_char_p, POINTER(c_char_p), c_int]
 lib.Convert.restype = c_char_p
+lib.ConvertJSON.argtypes = [c_char_p, POINTER(c_char_p), c_int]
+lib.ConvertJSON.restype = c_char_p
 
 # Test HTML
 test_html = b"""<!DOCTYPE html>
@@ -22,7 +25,7 @@ test_html = b"""<!DOCTYPE html>
 <body>
     <header><h1>This should be stripped</h1></header>
     <nav><a href="/">Home</a></nav>
-    <main><p>This content should remain</p></main>
+    <main><p>This content should remain</p><img src="chart.png" alt="Chart"></main>
     <footer><p>This should also be stripped</p></footer>
 </body>
 </html>"""
@@ -59,6 +62,28 @@ else:
     print("✗ Main content missing")
     failures += 1
 
+print("\nTesting ConvertJSON()...")
+doc = json.loads(lib.ConvertJSON(test_html, arr, len(strings)).decode('utf-8'))
+print(json.dumps(doc, indent=2))
+
+if doc.get("version") == 1 and doc["metadata"].get("title") == "Test":
+    print("\n✓ Versioned JSON with metadata")
+else:
+    print("\n✗ Missing version or metadata")
+    failures += 1
+
+if {"type": "paragraph", "text": "This content should remain"} in doc["blocks"]:
+    print("✓ Main content preserved as a paragraph block")
+else:
+    print("✗ Main content block missing")
+    failures += 1
+
+if {"type": "image", "alt": "Chart", "src": "chart.png"} in doc["blocks"]:
+    print("✓ Image kept as an image block")
+else:
+    print("✗ Image block missing")
+    failures += 1
+
 print("\n" + "="*50)
 
 if failures > 0:
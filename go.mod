@@ -2,4 +2,7 @@ module github.com/gremllm/lib
 
 go 1.25.5
 
-require golang.org/x/net v0.48.0
+require (
+	github.com/yuin/goldmark v1.8.2
+	golang.org/x/net v0.48.0
+)
@@ -0,0 +1,194 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Tags whose text is separated from its neighbours when flattened to a name
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "details": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "summary": true, "table": true, "td": true,
+	"th": true, "tr": true, "ul": true,
+}
+
+// Elements and roles whose accessible name may come from their content
+var (
+	nameFromContentTags = map[string]bool{
+		"a": true, "button": true, "summary": true, "caption": true, "legend": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"td": true, "th": true, "option": true, "label": true,
+	}
+	nameFromContentRoles = map[string]bool{
+		"button": true, "link": true, "tab": true, "menuitem": true, "option": true,
+		"heading": true, "cell": true, "columnheader": true, "rowheader": true,
+		"switch": true, "checkbox": true, "radio": true, "treeitem": true, "tooltip": true,
+	}
+)
+
+// accessibleName computes the accessible name of n following the WAI-ARIA
+// accessible name algorithm (accname 1.2), in its usual precedence:
+//  1. aria-labelledby, resolved through the referenced elements
+//  2. aria-label
+//  3. the host language label (alt, <label>, <caption>, <legend>, svg <title>, ...)
+//  4. the element's content, for roles that take their name from content
+//  5. the title attribute, then placeholder for text inputs
+func (ctx *mdContext) accessibleName(n *html.Node) string {
+	if name := ctx.idrefText(getAttr(n, "aria-labelledby")); name != "" {
+		return name
+	}
+	if name := collapseSpace(getAttr(n, "aria-label")); name != "" {
+		return name
+	}
+	if name := ctx.nativeName(n); name != "" {
+		return name
+	}
+	if nameFromContentTags[n.Data] || nameFromContentRoles[getAttr(n, "role")] {
+		if name := ctx.nameFromContent(n); name != "" {
+			return name
+		}
+	}
+	if name := collapseSpace(getAttr(n, "title")); name != "" {
+		return name
+	}
+	if n.Data == "input" || n.Data == "textarea" {
+		return collapseSpace(getAttr(n, "placeholder"))
+	}
+	return ""
+}
+
+// nativeName returns the text alternative HTML itself defines for n
+func (ctx *mdContext) nativeName(n *html.Node) string {
+	switch n.Data {
+	case "img", "area":
+		return collapseSpace(getAttr(n, "alt"))
+	case "svg":
+		if title := findChild(n, "title"); title != nil {
+			return textContent(title)
+		}
+	case "figure":
+		if fc := findChild(n, "figcaption"); fc != nil {
+			return textContent(fc)
+		}
+	case "table":
+		if caption := findChild(n, "caption"); caption != nil {
+			return textContent(caption)
+		}
+	case "fieldset":
+		if legend := findChild(n, "legend"); legend != nil {
+			return textContent(legend)
+		}
+	case "input":
+		switch strings.ToLower(getAttr(n, "type")) {
+		case "submit", "reset", "button":
+			if value := collapseSpace(getAttr(n, "value")); value != "" {
+				return value
+			}
+			if hasAttr(n, "type", "submit") {
+				return "Submit"
+			}
+			if hasAttr(n, "type", "reset") {
+				return "Reset"
+			}
+			return ""
+		case "image":
+			return collapseSpace(getAttr(n, "alt"))
+		}
+		return ctx.controlLabel(n)
+	case "select", "textarea", "meter", "progress", "output":
+		return ctx.controlLabel(n)
+	}
+	return ""
+}
+
+// nameFromContent flattens the content of n into a name. Hidden descendants are
+// skipped and embedded elements (images, icons) contribute their own names.
+func (ctx *mdContext) nameFromContent(n *html.Node) string {
+	var b strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				b.WriteString(c.Data)
+			case html.ElementNode:
+				if hasAttr(c, "aria-hidden", "true") || hasAttr(c, "data-llm", "drop") || hasAttrKey(c, "hidden") {
+					continue
+				}
+				switch c.Data {
+				case "script", "style", "template", "noscript":
+					continue
+				}
+				if blockTags[c.Data] {
+					b.WriteByte(' ')
+				}
+				embedded := ""
+				if c.Data == "img" || c.Data == "svg" || hasAttrKey(c, "aria-label") || hasAttrKey(c, "aria-labelledby") {
+					embedded = ctx.accessibleName(c)
+				}
+				if embedded != "" {
+					b.WriteByte(' ')
+					b.WriteString(embedded)
+					b.WriteByte(' ')
+				} else if c.Data != "svg" {
+					f(c)
+				}
+				if blockTags[c.Data] {
+					b.WriteByte(' ')
+				}
+			}
+		}
+	}
+	f(n)
+	return collapseSpace(b.String())
+}
+
+// controlLabel resolves the <label> of a form control: an explicit <label for=id>
+// first, then a wrapping <label>.
+func (ctx *mdContext) controlLabel(n *html.Node) string {
+	if id := getAttr(n, "id"); id != "" {
+		if label := ctx.labelFor(id); label != nil {
+			if text := labelText(label); text != "" {
+				return text
+			}
+		}
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "label" {
+			return labelText(p)
+		}
+	}
+	return ""
+}
+
+// isDecorativeImage reports whether an image opts out of the accessibility tree
+// with an explicit empty alt or a presentational role.
+func isDecorativeImage(n *html.Node) bool {
+	if role := getAttr(n, "role"); role == "presentation" || role == "none" {
+		return true
+	}
+	return hasAttr(n, "alt", "") && !hasAttrKey(n, "aria-label") && !hasAttrKey(n, "aria-labelledby")
+}
+
+// linkTarget describes mailto: and tel: links in words, since the raw URL scheme
+// adds little for a reader. ok is false for ordinary links.
+func linkTarget(href string) (kind, value string, ok bool) {
+	lower := strings.ToLower(href)
+	switch {
+	case strings.HasPrefix(lower, "mailto:"):
+		kind, value = "Email", href[len("mailto:"):]
+	case strings.HasPrefix(lower, "tel:"):
+		kind, value = "Phone", href[len("tel:"):]
+	default:
+		return "", "", false
+	}
+	if i := strings.IndexByte(value, '?'); i >= 0 {
+		value = value[:i]
+	}
+	return kind, strings.TrimSpace(value), true
+}
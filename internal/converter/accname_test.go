@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_LinkAccessibleName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"svg title icon", `<a href="/cart"><svg><title>Cart</title><path d=""/></svg></a>`, "[Cart](/cart)"},
+		{"aria-label", `<a href="/cart" aria-label="Shopping cart"><svg></svg></a>`, "[Shopping cart](/cart)"},
+		{"aria-label overrides text", `<a href="/docs" aria-label="Read the docs">More</a>`, "[Read the docs](/docs)"},
+		{"aria-labelledby", `<span id="l1">Account</span><a href="/me" aria-labelledby="l1"><i></i></a>`, "[Account](/me)"},
+		{"title fallback", `<a href="/help" title="Help center"><i class="icon"></i></a>`, "[Help center](/help)"},
+		{"image alt", `<a href="/"><img src="logo.png" alt="Home"></a>`, "[[Image: Home]](/)"},
+		{"no name uses href", `<a href="/search"><i></i></a>`, "[/search](/search)"},
+		{"visually hidden text", `<a href="/x"><svg aria-hidden="true"></svg><span class="sr-only">Close</span></a>`, "[Close](/x)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body><p>" + tt.input + "</p></body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected %q, got: %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_LinkWithoutHref(t *testing.T) {
+	input := []byte(`<html><body><p>Start <a><i></i></a><a name="top">Anchor text</a> end</p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if strings.Contains(result, "[") || strings.Contains(result, "](") {
+		t.Errorf("Links without href should not render as links, got: %s", result)
+	}
+	if !strings.Contains(result, "Anchor text") {
+		t.Errorf("Anchor text should be kept, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_MailtoTelLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"mailto bare", `<a href="mailto:help@example.com">help@example.com</a>`, "Email: help@example.com"},
+		{"mailto with text", `<a href="mailto:help@example.com?subject=Hi">Contact us</a>`, "Contact us (email: help@example.com)"},
+		{"tel", `<a href="tel:+15551234567"><svg aria-hidden="true"></svg></a>`, "Phone: +15551234567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body><p>" + tt.input + "</p></body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected %q, got: %s", tt.expected, result)
+			}
+			if strings.Contains(result, "](mailto:") || strings.Contains(result, "](tel:") {
+				t.Errorf("Raw scheme should not be rendered, got: %s", result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_ButtonAccessibleName(t *testing.T) {
+	input := []byte(`<html><body><p>Text <button aria-label="Close dialog"><svg></svg></button></p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if !strings.Contains(result, "Close dialog") {
+		t.Errorf("Expected button name, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_ImageAccessibleName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"aria-label", `<img src="a.png" aria-label="Team photo">`, "[Image: Team photo]"},
+		{"title", `<img src="a.png" title="Office">`, "[Image: Office]"},
+		{"decorative alt", `<img src="divider.png" alt="">`, ""},
+		{"presentation role", `<img src="a.png" alt="x" role="presentation">`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body>" + tt.input + "</body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestProcessHTML_ImageAccessibleName(t *testing.T) {
+	input := `<html><body><span id="cap">Sunset</span><img src="x.jpg" aria-labelledby="cap"></body></html>`
+	result, _ := ProcessHTML([]byte(input), StripConfig{})
+
+	if !strings.Contains(string(result), "[Image: Sunset]") {
+		t.Errorf("Expected labelled image, got: %s", result)
+	}
+}
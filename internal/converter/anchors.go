@@ -0,0 +1,149 @@
+package converter
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// AnchorStyle controls whether headings keep their id as a link target
+type AnchorStyle int
+
+const (
+	AnchorsNone      AnchorStyle = iota // No anchors (default)
+	AnchorsAttribute                    // "## Pricing {#pricing}"
+	AnchorsHTML                         // "## <a id="pricing"></a>Pricing"
+)
+
+// ErrSectionNotFound is returned when StripConfig.Section names no element, or
+// StripConfig.Select matches none. Convert returns it with the page's outline,
+// so the caller can list the sections that exist.
+var ErrSectionNotFound = errors.New("section not found")
+
+// Elements whose id names the section their first heading introduces
+var sectioningTags = map[string]bool{"section": true, "article": true, "main": true}
+
+// headingAnchor returns the anchor of a heading rendered in the document,
+// recording it so sectionNodes can resolve the anchor back to the heading
+func (ctx *mdContext) headingAnchor(n *html.Node) string {
+	if id, ok := ctx.anchors[n]; ok {
+		return id
+	}
+	if ctx.anchorsUsed == nil {
+		ctx.anchorsUsed = make(map[string]bool)
+	}
+	if ctx.anchors == nil {
+		ctx.anchors = make(map[*html.Node]string)
+	}
+	id := anchorFor(n, ctx.anchorsUsed)
+	ctx.anchors[n] = id
+	return id
+}
+
+// anchorFor returns the id a heading is reachable by: its own, that of the
+// section it introduces, or a slug of its text. Anchors are unique within
+// used, taken ones get a numeric suffix ("setup-1").
+func anchorFor(n *html.Node, used map[string]bool) string {
+	id := getAttr(n, "id")
+	if id == "" {
+		for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+			if sectioningTags[p.Data] || hasAttr(p, "role", "region") {
+				if findHeading(p) == n {
+					id = getAttr(p, "id")
+				}
+				break
+			}
+		}
+	}
+	if id == "" || used[id] {
+		slug := slugify(textContent(n))
+		if slug == "" {
+			return ""
+		}
+		id = slug
+		for i := 1; used[id]; i++ {
+			id = slug + "-" + strconv.Itoa(i)
+		}
+	}
+	used[id] = true
+	return id
+}
+
+// slugify turns heading text into an anchor: lowercase letters and digits
+// joined by single hyphens
+func slugify(s string) string {
+	var b strings.Builder
+	gap := false
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			gap = true
+			continue
+		}
+		if gap && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		gap = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sectionNodes returns the nodes that make up the section with the given id.
+// An element id selects that element; other ids are matched against the
+// anchors of the headings rendered so far (see headingAnchor). A heading id selects the section the
+// heading introduces: its sectioning parent, or the heading and the siblings
+// up to the next heading of the same or a higher level. It returns nil when
+// the page does not render the target.
+func (ctx *mdContext) sectionNodes(id string) []*html.Node {
+	target := ctx.byID(id)
+	if target == nil {
+		target = ctx.headingBySlug(id)
+	}
+	// A section the page leaves out is not served on its own either
+	if target == nil || !ctx.reachable(target) {
+		return nil
+	}
+	if !headingTags[target.Data] {
+		return []*html.Node{target}
+	}
+
+	if p := target.Parent; p != nil && (sectioningTags[p.Data] || hasAttr(p, "role", "region")) && findHeading(p) == target {
+		return []*html.Node{p}
+	}
+
+	level := target.Data[1]
+	nodes := []*html.Node{target}
+	for s := target.NextSibling; s != nil; s = s.NextSibling {
+		if h := findHeading(s); h != nil && h.Data[1] <= level {
+			break
+		}
+		nodes = append(nodes, s)
+	}
+	return nodes
+}
+
+// headingBySlug returns the rendered heading whose anchor is id
+func (ctx *mdContext) headingBySlug(id string) *html.Node {
+	for n, anchor := range ctx.anchors {
+		if anchor == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// findHeading returns the first heading at or below n in document order
+func findHeading(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && headingTags[n.Data] {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if h := findHeading(c); h != nil {
+			return h
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+)
+
+const anchoredPage = `<html><body>
+	<h1 id="top">Product</h1>
+	<p>Intro</p>
+	<section id="pricing"><h2>Pricing</h2><p>$10 a month.</p><h3>Discounts</h3><p>For teams.</p></section>
+	<h2 id="faq">Questions</h2>
+	<p>Ask us.</p>
+	<h3>Refunds</h3>
+	<p>Within 30 days.</p>
+	<h2>Contact</h2>
+	<p>Email us.</p>
+</body></html>`
+
+func TestHTMLToMarkdown_AnchorStyles(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    AnchorStyle
+		expected string
+	}{
+		{"none", AnchorsNone, "# Product\n\nIntro\n\n## Pricing\n\n$10 a month.\n\n### Discounts"},
+		{"attribute", AnchorsAttribute, "# Product {#top}\n\nIntro\n\n## Pricing {#pricing}\n\n$10 a month.\n\n### Discounts {#discounts}"},
+		{"html", AnchorsHTML, "# <a id=\"top\"></a>Product\n\nIntro\n\n## <a id=\"pricing\"></a>Pricing\n\n$10 a month.\n\n### <a id=\"discounts\"></a>Discounts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := HTMLToMarkdown([]byte(anchoredPage), StripConfig{Anchors: tt.style})
+			if result[:len(tt.expected)] != tt.expected {
+				t.Errorf("Expected prefix %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_Section(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected string
+	}{
+		{"pricing", "## Pricing {#pricing}\n\n$10 a month.\n\n### Discounts {#discounts}\n\nFor teams."},
+		{"faq", "## Questions {#faq}\n\nAsk us.\n\n### Refunds {#refunds}\n\nWithin 30 days."},
+		{"refunds", "### Refunds {#refunds}\n\nWithin 30 days."},
+		{"contact", "## Contact {#contact}\n\nEmail us."},
+		{"top", "# Product {#top}\n\nIntro\n\n## Pricing {#pricing}\n\n$10 a month.\n\n### Discounts {#discounts}\n\nFor teams.\n\n## Questions {#faq}\n\nAsk us.\n\n### Refunds {#refunds}\n\nWithin 30 days.\n\n## Contact {#contact}\n\nEmail us."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			result, err := HTMLToMarkdown([]byte(anchoredPage), StripConfig{Anchors: AnchorsAttribute, Section: tt.id})
+			if err != nil {
+				t.Fatalf("HTMLToMarkdown failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_SectionNotFound(t *testing.T) {
+	_, err := HTMLToMarkdown([]byte(anchoredPage), StripConfig{Section: "missing"})
+	if !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got: %v", err)
+	}
+}
+
+func TestHTMLToMarkdown_SectionExcluded(t *testing.T) {
+	input := []byte(`<html><body>
+		<nav id="menu"><h2>Menu</h2><p>Links</p></nav>
+		<div data-llm="drop"><section id="draft"><h2>Secret</h2><p>draft</p></section></div>
+		<div hidden><h2 id="old">Old</h2><p>Gone</p></div>
+		<div style="font-size:0"><h2 id="tiny">Tiny</h2><p>Hidden</p></div>
+		<section id="live"><h2>Live</h2><p>Shown</p></section>
+	</body></html>`)
+
+	for _, id := range []string{"menu", "draft", "secret", "old", "tiny"} {
+		cfg := StripConfig{Section: id, SkipHidden: true, Defense: DefenseStrip}
+		if result, err := HTMLToMarkdown(input, cfg); !errors.Is(err, ErrSectionNotFound) {
+			t.Errorf("%s: expected ErrSectionNotFound, got %q, %v", id, result, err)
+		}
+	}
+	result, err := HTMLToMarkdown(input, StripConfig{Section: "live", SkipHidden: true, Defense: DefenseStrip})
+	if err != nil || result != "## Live\n\nShown" {
+		t.Errorf("Expected the live section, got %q, %v", result, err)
+	}
+}
+
+func TestHTMLToMarkdown_SectionSlugsMatchOutline(t *testing.T) {
+	// The stripped nav heading takes no slug, so the page's second "Setup" is setup-1
+	input := []byte(`<html><body>
+		<nav><h2>Setup</h2></nav>
+		<h2>Setup</h2><p>First</p>
+		<h2>Setup</h2><p>Second</p>
+	</body></html>`)
+
+	result, _ := HTMLToMarkdown(input, StripConfig{Outline: OutlineOnly})
+	if expected := "- [Setup](#setup) (~4 tokens)\n- [Setup](#setup-1) (~4 tokens)"; result != expected {
+		t.Fatalf("Expected outline %q, got: %q", expected, result)
+	}
+	for id, expected := range map[string]string{
+		"setup":   "## Setup {#setup}\n\nFirst",
+		"setup-1": "## Setup {#setup-1}\n\nSecond",
+	} {
+		result, err := HTMLToMarkdown(input, StripConfig{Anchors: AnchorsAttribute, Section: id})
+		if err != nil || result != expected {
+			t.Errorf("%s: expected %q, got %q, %v", id, expected, result, err)
+		}
+	}
+}
+
+func TestHTMLToMarkdown_AnchorAttributeIDs(t *testing.T) {
+	input := []byte(`<html><body><h2 id="a b">Spaced</h2><h2 id="x}">Braced</h2><h2 id="ok-1">Fine</h2></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{Anchors: AnchorsAttribute})
+
+	if expected := "## Spaced\n\n## Braced\n\n## Fine {#ok-1}"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
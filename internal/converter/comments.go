@@ -0,0 +1,148 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// CommentMode controls how user comment threads are rendered
+type CommentMode int
+
+const (
+	CommentsKeep     CommentMode = iota // Render comments in place (default)
+	CommentsDrop                        // Leave comments out
+	CommentsCount                       // Replace comments with "[N comments]"
+	CommentsSeparate                    // Move comments under a "## Comments" section at the end
+)
+
+// Containers of a comment thread (WordPress, Ghost, common themes)
+var commentSectionClasses = []string{
+	"comments", "comment-list", "commentlist", "comments-area", "comment-section", "comments-section",
+}
+
+// commentGroups holds the comment threads of a document. Adjacent comments
+// without a shared container form one group.
+type commentGroups struct {
+	groups map[*html.Node][]*html.Node // First root of a group -> all its roots
+	rest   map[*html.Node]bool         // Roots after the first in their group
+}
+
+// collectComments finds comment sections and standalone comments. Code
+// blocks are not searched, as highlighters use "comment" as a token class.
+func collectComments(doc *html.Node) *commentGroups {
+	cg := &commentGroups{groups: make(map[*html.Node][]*html.Node), rest: make(map[*html.Node]bool)}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		var first, last *html.Node
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.Data == "pre" || c.Data == "code" {
+				last = nil
+				continue
+			}
+			if !isCommentSection(c) && !isComment(c) {
+				f(c)
+				last = nil
+				continue
+			}
+			if last != nil {
+				cg.groups[first] = append(cg.groups[first], c)
+				cg.rest[c] = true
+			} else {
+				first = c
+				cg.groups[c] = []*html.Node{c}
+			}
+			last = c
+		}
+	}
+	f(doc)
+
+	if len(cg.groups) == 0 {
+		return nil
+	}
+	return cg
+}
+
+// renderComments applies the comment mode to a comment group. It reports
+// whether n was consumed.
+func (ctx *mdContext) renderComments(n *html.Node) bool {
+	if ctx.comments.rest[n] {
+		return true
+	}
+	roots, ok := ctx.comments.groups[n]
+	if !ok {
+		return false
+	}
+
+	switch ctx.commentMode {
+	case CommentsCount:
+		count := 0
+		for _, root := range roots {
+			count += countComments(root)
+		}
+		switch {
+		case count == 1:
+			ctx.text("\n[1 comment]\n\n")
+		case count > 1:
+			ctx.text("\n[" + itoa(count) + " comments]\n\n")
+		}
+	case CommentsSeparate:
+		// Render the thread with comment handling off so roots render normally
+		groups := ctx.comments
+		ctx.comments = nil
+		for _, root := range roots {
+			thread := &Section{}
+			thread.Children = ctx.build(func() { ctx.walk(root) })
+			ctx.movedComments = append(ctx.movedComments, thread)
+		}
+		ctx.comments = groups
+	}
+	return true
+}
+
+// renderMovedComments adds the comments collected in separate mode
+func (ctx *mdContext) renderMovedComments() {
+	if len(ctx.movedComments) == 0 {
+		return
+	}
+	ctx.add(&Comments{branch: branch{Children: ctx.movedComments}})
+}
+
+func isCommentSection(n *html.Node) bool {
+	return getAttr(n, "id") == "comments" || hasAnyClass(n, commentSectionClasses)
+}
+
+// isComment reports whether n is a single comment: class="comment", schema.org
+// Comment microdata, or role="article" inside a <section>
+func isComment(n *html.Node) bool {
+	if hasClass(n, "comment") || hasItemType(n, "Comment") {
+		return true
+	}
+	if !strings.Contains(" "+getAttr(n, "role")+" ", " article ") {
+		return false
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "section" {
+			return true
+		}
+	}
+	return false
+}
+
+// countComments counts the comments in a subtree, replies included
+func countComments(n *html.Node) int {
+	count := 0
+	if isComment(n) {
+		count++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data != "pre" && c.Data != "code" {
+			count += countComments(c)
+		}
+	}
+	return count
+}
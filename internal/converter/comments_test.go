@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"testing"
+)
+
+const blogWithComments = `<html><body>
+	<article><h1>Post</h1><p>Article body.</p><pre><code><span class="token comment">// keep me</span></code></pre></article>
+	<div id="comments" class="comments-area">
+		<h2>2 thoughts</h2>
+		<ol class="comment-list">
+			<li class="comment depth-1"><p>Great post!</p>
+				<ol class="children"><li class="comment depth-2"><p>Agreed.</p></li></ol>
+			</li>
+		</ol>
+	</div>
+	<p>Related reading</p>
+</body></html>`
+
+func TestHTMLToMarkdown_CommentModes(t *testing.T) {
+	article := "# Post\n\nArticle body.\n\n```\n// keep me\n```"
+	tests := []struct {
+		name     string
+		mode     CommentMode
+		expected string
+	}{
+		{"keep", CommentsKeep, article + "\n\n## 2 thoughts\n\n1. Great post!\n\n  1. Agreed.\n\nRelated reading"},
+		{"drop", CommentsDrop, article + "\n\nRelated reading"},
+		{"count", CommentsCount, article + "\n\n[2 comments]\n\nRelated reading"},
+		{"separate", CommentsSeparate, article + "\n\nRelated reading\n\n## Comments\n\n## 2 thoughts\n\n1. Great post!\n\n  1. Agreed."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := HTMLToMarkdown([]byte(blogWithComments), StripConfig{Comments: tt.mode})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_StandaloneComments(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>Thread</p>
+		<section>
+			<div role="article"><p>First reply</p></div>
+			<div role="article"><p>Second reply</p></div>
+		</section>
+		<div itemscope itemtype="https://schema.org/Comment"><p>Microdata reply</p></div>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{Comments: CommentsCount})
+
+	expected := "Thread\n\n[2 comments]\n\n[1 comment]"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
@@ -11,11 +11,57 @@ import (
 
 type StripConfig struct {
 	ElementsToStrip   []string
-	RemoveImagesNoAlt bool // If true, remove images without alt text entirely
+	RemoveImagesNoAlt bool          // If true, remove images without alt text entirely
+	SkipHidden        bool          // If true, skip content a browser would not display (see isHidden)
+	Defense           DefenseMode   // Handling of hidden text and prompt-injection attempts
+	Noscript          NoscriptMode  // When to render <noscript> fallback content
+	State             StateConfig   // Embedded SPA state rendered as content
+	SVG               SVGMode       // Describe inline SVG instead of dropping it
+	Comments          CommentMode   // Keep, drop, count or move user comment threads
+	Headings          HeadingConfig // Heading hierarchy normalization
+	Anchors           AnchorStyle   // Keep heading ids as link targets
+	Section           string        // Render only the section with this id (see ErrSectionNotFound)
+	Select            string        // Render only elements matching this CSS selector; Section takes precedence
+	Outline           OutlineMode   // Add a table of contents with per-section token estimates
+	Dialect           Dialect       // Markdown syntax of the output
+	Tables            TableConfig   // Compact CSV, TSV or TONL tables and a row limit
 }
 
 // Default elements to strip - users can preserve with data-llm="keep"
-var defaultStripElements = []string{"nav", "aside", "footer", "header", "script", "style", "noscript", "svg", "iframe"}
+var defaultStripElements = []string{"nav", "aside", "footer", "header", "search", "script", "style", "noscript", "svg", "iframe"}
+
+// ARIA landmark roles mapped to the element that carries the role implicitly.
+// Stripping is decided on this effective tag, so <div role="navigation"> goes
+// wherever <nav> goes and <div role="main"> is treated like <main>.
+var landmarkRoles = map[string]string{
+	"navigation":    "nav",
+	"banner":        "header",
+	"contentinfo":   "footer",
+	"complementary": "aside",
+	"search":        "search",
+	"main":          "main",
+}
+
+// effectiveTag returns the tag an element behaves as: the landmark its role
+// names, or its own tag name.
+func effectiveTag(n *html.Node) string {
+	for _, role := range strings.Fields(getAttr(n, "role")) {
+		if tag, ok := landmarkRoles[role]; ok {
+			return tag
+		}
+	}
+	return n.Data
+}
+
+// shouldStrip reports whether an element is removed by the strip set: its
+// effective tag is listed, or it is hidden from assistive technology with
+// aria-hidden="true". data-llm="keep" overrides both.
+func shouldStrip(n *html.Node, stripSet map[string]bool) bool {
+	if n.Type != html.ElementNode || hasAttr(n, "data-llm", "keep") {
+		return false
+	}
+	return stripSet[effectiveTag(n)] || hasAttr(n, "aria-hidden", "true")
+}
 
 // ProcessScripts handles script tags with data-llm-description attribute.
 // If a script has data-llm-description, it is replaced with a descriptive text node.
@@ -69,9 +115,10 @@ func ProcessScripts(n *html.Node) {
 	f(n)
 }
 
-// ProcessImages replaces img tags with their alt text.
+// ProcessImages replaces img tags with their accessible name (usually the alt text).
 // Format: "[Image: alt text]" or "[Image]" if no alt.
-// If removeIfNoAlt is true and no alt text exists, the image is removed entirely.
+// Decorative images (alt="" or role="presentation") are removed, and if removeIfNoAlt
+// is true, images with no text alternative are removed entirely as well.
 func ProcessImages(n *html.Node, removeIfNoAlt bool) {
 	type imageReplacement struct {
 		node   *html.Node
@@ -79,21 +126,17 @@ func ProcessImages(n *html.Node, removeIfNoAlt bool) {
 		remove bool
 	}
 
+	// Names are resolved against the whole document (aria-labelledby)
+	names := &mdContext{doc: n}
+
 	var f func(*html.Node)
 	f = func(parent *html.Node) {
 		var toProcess []imageReplacement
 
 		for c := parent.FirstChild; c != nil; c = c.NextSibling {
 			if c.Type == html.ElementNode && c.Data == "img" {
-				var altText string
-				for _, attr := range c.Attr {
-					if attr.Key == "alt" {
-						altText = attr.Val
-						break
-					}
-				}
-
-				shouldRemove := altText == "" && removeIfNoAlt
+				altText := names.accessibleName(c)
+				shouldRemove := (altText == "" && removeIfNoAlt) || isDecorativeImage(c)
 				toProcess = append(toProcess, imageReplacement{
 					node:   c,
 					alt:    altText,
@@ -126,7 +169,8 @@ func ProcessImages(n *html.Node, removeIfNoAlt bool) {
 	f(n)
 }
 
-// StripElements removes specified HTML elements from the DOM
+// StripElements removes specified HTML elements from the DOM, along with
+// elements whose landmark role maps to one of them and aria-hidden elements
 func StripElements(n *html.Node, tags ...string) {
 	tagSet := make(map[string]bool, len(tags))
 	for _, tag := range tags {
@@ -139,16 +183,11 @@ func StripElements(n *html.Node, tags ...string) {
 		var toRemove []*html.Node
 
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			if c.Type == html.ElementNode && tagSet[c.Data] {
-				shouldKeep := false
-				for _, attr := range c.Attr {
-					if attr.Key == "data-llm" && attr.Val == "keep" {
-						shouldKeep = true
-						break
-					}
-				}
-				if !shouldKeep {
+			if c.Type == html.ElementNode && (tagSet[effectiveTag(c)] || hasAttr(c, "aria-hidden", "true")) {
+				if shouldStrip(c, tagSet) {
 					toRemove = append(toRemove, c)
+				} else {
+					f(c)
 				}
 			} else {
 				shouldKeep := true
@@ -212,17 +251,10 @@ var noisePatterns = []string{
 // Pre-compiled regex for whitespace normalization
 var multipleNewlines = regexp.MustCompile(`\n{3,}`)
 
-// CondenseMarkdown optimizes markdown for LLM consumption by:
-// - Removing noise (attributions, decorative text)
-// - Fixing fragmented numbered lists
-// - Normalizing whitespace
+// CondenseMarkdown normalizes the whitespace of rendered markdown: runs of
+// blank lines collapse to one, and trailing whitespace is trimmed from lines
+// and the document. Content cleanup happens on the model (see optimize).
 func CondenseMarkdown(md string) string {
-	lines := strings.Split(md, "\n")
-	lines = removeNoiseLines(lines)
-	lines = fixFragmentedLists(lines)
-
-	md = strings.Join(lines, "\n")
-
 	// Collapse 3+ newlines to 2
 	md = multipleNewlines.ReplaceAllString(md, "\n\n")
 
@@ -236,29 +268,6 @@ func CondenseMarkdown(md string) string {
 	return strings.TrimSpace(md)
 }
 
-// removeNoiseLines filters out attribution, copyright, and decorative noise
-func removeNoiseLines(lines []string) []string {
-	var result []string
-	for _, line := range lines {
-		lower := strings.ToLower(strings.TrimSpace(line))
-		if lower == "" {
-			result = append(result, line)
-			continue
-		}
-		isNoise := false
-		for _, pattern := range noisePatterns {
-			if strings.Contains(lower, pattern) {
-				isNoise = true
-				break
-			}
-		}
-		if !isNoise {
-			result = append(result, line)
-		}
-	}
-	return result
-}
-
 // isStandaloneNumber checks if a line is just a number (1-99)
 func isStandaloneNumber(s string) bool {
 	s = strings.TrimSpace(s)
@@ -273,69 +282,6 @@ func isStandaloneNumber(s string) bool {
 	return true
 }
 
-// fixFragmentedLists converts standalone numbers followed by content into proper list items
-func fixFragmentedLists(lines []string) []string {
-	var result []string
-	i := 0
-
-	for i < len(lines) {
-		line := lines[i]
-		trimmed := strings.TrimSpace(line)
-
-		// Check for standalone number (fragmented list)
-		if isStandaloneNumber(trimmed) {
-			num := trimmed
-			i++
-
-			// Skip empty lines
-			for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-				i++
-			}
-
-			// Collect content until next number or heading
-			var parts []string
-			for i < len(lines) {
-				next := strings.TrimSpace(lines[i])
-				if next == "" {
-					// Peek ahead
-					j := i + 1
-					for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
-						j++
-					}
-					if j < len(lines) {
-						peek := strings.TrimSpace(lines[j])
-						if isStandaloneNumber(peek) || strings.HasPrefix(peek, "#") {
-							break
-						}
-					} else {
-						break
-					}
-					i++
-					continue
-				}
-				if isStandaloneNumber(next) || strings.HasPrefix(next, "#") {
-					break
-				}
-				parts = append(parts, next)
-				i++
-			}
-
-			if len(parts) > 0 {
-				// Join parts: "num. part1 - part2 - part3..."
-				result = append(result, num+". "+strings.Join(parts, " - "))
-			} else {
-				result = append(result, line)
-			}
-			continue
-		}
-
-		result = append(result, line)
-		i++
-	}
-
-	return result
-}
-
 // Buffer pool to reduce allocations
 var bufPool = sync.Pool{
 	New: func() any {
@@ -353,14 +299,125 @@ func putBuffer(buf *strings.Builder) {
 	bufPool.Put(buf)
 }
 
+// Result is the outcome of a conversion
+type Result struct {
+	Markdown string
+	Warnings []Warning // Suspicious content found when Defense is enabled
+	FAQ      []FAQ     // Question and answer pairs, in page order
+	Outline  []OutlineEntry
+	Metadata Metadata
+	Document *Document // The document model the markdown was rendered from
+}
+
 // HTMLToMarkdown converts HTML to markdown in a single pass.
 // It processes, strips, and converts in one tree walk.
 func HTMLToMarkdown(htmlContent []byte, stripConfig StripConfig) (string, error) {
+	result, err := Convert(htmlContent, stripConfig)
+	return result.Markdown, err
+}
+
+// HTMLToText converts HTML to plain text for pipelines where markdown syntax
+// is noise. It strips and extracts like HTMLToMarkdown, rendering in
+// DialectPlain: paragraph breaks and list bullets stay, while link URLs,
+// emphasis markers, heading marks and code fences go.
+func HTMLToText(htmlContent []byte, stripConfig StripConfig) (string, error) {
+	stripConfig.Dialect = DialectPlain
+	return HTMLToMarkdown(htmlContent, stripConfig)
+}
+
+// Convert converts HTML to markdown like HTMLToMarkdown and also reports
+// what it found along the way.
+func Convert(htmlContent []byte, stripConfig StripConfig) (Result, error) {
 	doc, err := html.Parse(bytes.NewReader(htmlContent))
 	if err != nil {
-		return "", err
+		return Result{}, err
 	}
 
+	model := &Document{}
+	ctx := newContext(doc, stripConfig)
+	ctx.out = &model.Children
+
+	// A section or selector that matches nothing renders the whole page, for
+	// the outline returned with ErrSectionNotFound
+	var nodes []*html.Node
+	switch {
+	case stripConfig.Section != "":
+		// Slugs name headings as the whole page renders them: walk the page
+		// first, and let the section keep the page's anchors
+		page := newContext(doc, stripConfig)
+		page.out = &(&Document{}).Children
+		page.walk(doc)
+		ctx.anchors, ctx.anchorsUsed = page.anchors, page.anchorsUsed
+		nodes = ctx.sectionNodes(stripConfig.Section)
+	case stripConfig.Select != "":
+		groups, err := parseSelector(stripConfig.Select)
+		if err != nil {
+			return Result{}, err
+		}
+		// Matches the page leaves out (stripped, dropped, hidden) are not served
+		for _, n := range selectNodes(doc, groups) {
+			if ctx.reachable(n) {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	notFound := nodes == nil && (stripConfig.Section != "" || stripConfig.Select != "")
+	switch {
+	case nodes == nil:
+		ctx.walk(doc)
+	case stripConfig.Section != "":
+		for _, n := range nodes {
+			ctx.walk(n)
+		}
+	default:
+		for _, n := range nodes {
+			ctx.walk(n)
+			ctx.text("\n\n")
+		}
+	}
+	ctx.renderMovedComments()
+	ctx.renderFootnotes()
+	optimize(model)
+	md := ctx.markdown(model)
+
+	faq := ctx.md.faq(model)
+	if ctx.defense != DefenseOff {
+		for i := range faq {
+			faq[i].Question, _ = screenInstructions(faq[i].Question, ctx.defense)
+			faq[i].Answer, _ = screenInstructions(faq[i].Answer, ctx.defense)
+		}
+	}
+
+	outline, lines := ctx.md.outline(model)
+	sizeSections(md, outline, lines)
+	if notFound {
+		return Result{Outline: outline}, ErrSectionNotFound
+	}
+	if stripConfig.Outline != OutlineNone && len(outline) > 0 {
+		toc := FormatTOC(outline)
+		if ctx.defense != DefenseOff {
+			// Heading text was screened in md already; the warnings are not repeated
+			toc, _ = screenInstructions(toc, ctx.defense)
+		}
+		if stripConfig.Outline == OutlineOnly {
+			md = strings.TrimSuffix(toc, "\n")
+		} else {
+			md = ctx.md.headingPrefix(2) + "Contents\n\n" + toc + "\n" + md
+		}
+	}
+
+	return Result{
+		Markdown: md,
+		Warnings: ctx.warnings,
+		FAQ:      faq,
+		Outline:  outline,
+		Metadata: collectMetadata(doc),
+		Document: model,
+	}, nil
+}
+
+// newContext prepares the walk of doc. The caller sets out before walking.
+func newContext(doc *html.Node, stripConfig StripConfig) *mdContext {
 	// Build strip set
 	stripSet := make(map[string]bool)
 	for _, tag := range defaultStripElements {
@@ -370,22 +427,47 @@ func HTMLToMarkdown(htmlContent []byte, stripConfig StripConfig) (string, error)
 		stripSet[tag] = true
 	}
 
-	buf := getBuffer()
-	defer putBuffer(buf)
-
 	ctx := &mdContext{
-		buf:             buf,
+		md:              newMarkdownRenderer(stripConfig),
+		doc:             doc,
 		stripSet:        stripSet,
 		removeImgNoAlt:  stripConfig.RemoveImagesNoAlt,
+		skipHidden:      stripConfig.SkipHidden,
+		defense:         stripConfig.Defense,
+		showNoscript:    shouldRenderNoscript(doc, stripConfig.Noscript, stripSet),
+		state:           stripConfig.State,
+		svgMode:         stripConfig.SVG,
+		commentMode:     stripConfig.Comments,
+		headings:        stripConfig.Headings,
 		inPre:           false,
 		listDepth:       0,
 		orderedListNums: make([]int, 10),
 	}
 
-	ctx.walk(doc)
+	ctx.notes = ctx.collectFootnotes()
+	ctx.widgets = ctx.collectWidgets()
+	if ctx.commentMode != CommentsKeep {
+		ctx.comments = collectComments(doc)
+	}
+	return ctx
+}
 
-	result := buf.String()
-	return CondenseMarkdown(result), nil
+// markdown renders the finished model and screens it for injected
+// instructions, adding what it finds to the warnings
+func (ctx *mdContext) markdown(model *Document) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	ctx.md.write(buf, model.Children)
+	md := CondenseMarkdown(buf.String())
+	if ctx.defense != DefenseOff {
+		var found []Warning
+		md, found = screenInstructions(md, ctx.defense)
+		ctx.warnings = append(ctx.warnings, found...)
+		if len(found) > 0 && ctx.defense == DefenseStrip {
+			md = CondenseMarkdown(md)
+		}
+	}
+	return md
 }
 
 // Markdown element rendering rules
@@ -397,13 +479,6 @@ type mdRule struct {
 var (
 	// Simple wrap rules: prefix + children + suffix
 	wrapRules = map[string]mdRule{
-		// Headings
-		"h1": {"\n# ", "\n\n"},
-		"h2": {"\n## ", "\n\n"},
-		"h3": {"\n### ", "\n\n"},
-		"h4": {"\n#### ", "\n\n"},
-		"h5": {"\n##### ", "\n\n"},
-		"h6": {"\n###### ", "\n\n"},
 
 		// Block elements
 		"p":          {"", "\n\n"},
@@ -430,11 +505,9 @@ var (
 		"samp": {" `", "` "},  // Sample output
 		"var":  {" _", "_ "},  // Variable
 		"dfn":  {" *", "* "},  // Definition term
-		"abbr": {"", ""},      // Abbreviation - just text
 		"cite": {" *", "* "},  // Citation
 
 		// Table elements
-		"table":   {"\n", "\n"},
 		"tr":      {"|", "\n"},
 		"th":      {" **", "** |"},
 		"td":      {" ", " |"},
@@ -449,15 +522,15 @@ var (
 		"details": {"\n", "\n"},
 		"summary": {"\n**", "**\n"},
 
-		// Time
-		"time": {"", ""},
-
 		// Ruby annotations (East Asian)
 		"ruby": {"", ""},
 		"rt":   {" (", ")"},
 		"rp":   {"", ""},
 	}
 
+	// Table structure that must render even when empty
+	tableCellTags = map[string]bool{"tr": true, "th": true, "td": true}
+
 	// Tags that just pass through to children (structural/container tags)
 	passThroughTags = map[string]bool{
 		// Document structure
@@ -468,15 +541,14 @@ var (
 		"header": true, "footer": true, "aside": true, "nav": true,
 		"hgroup": true, "search": true,
 
-		// Grouping
-		"span": true, "figure": true, "figcaption": true,
+		// Grouping (figure is rendered specially, orphan captions pass through)
+		"span": true, "figcaption": true,
 
-		// Forms (pass through, not interactive in markdown)
-		"form": true, "fieldset": true, "legend": true,
-		"label": true, "input": true, "button": true,
+		// Form controls outside a <form> (controls in forms are summarised by renderForm)
+		"fieldset": true, "legend": true,
+		"label": true, "input": true,
 		"select": true, "optgroup": true, "option": true,
 		"textarea": true, "output": true, "datalist": true,
-		"meter": true, "progress": true,
 
 		// Table grouping
 		"thead": true, "tbody": true, "tfoot": true,
@@ -495,15 +567,12 @@ var (
 		// Template (hidden content)
 		"template": true,
 
-		// Data
-		"data": true,
-
 		// Dialog
 		"dialog": true,
 
 		// Deprecated but may appear
 		"center": true, "font": true, "big": true, "tt": true,
-		"strike": true, "acronym": true, "dir": true,
+		"strike": true, "dir": true,
 	}
 
 	// Tags to skip entirely (no output, no children)
@@ -516,10 +585,35 @@ var (
 	}
 )
 
+// mdContext walks the HTML tree and builds the document model
 type mdContext struct {
-	buf             *strings.Builder
+	out             *[]Node           // Node list being built, see add and build
+	md              *markdownRenderer // Renders built nodes where the walker needs their text
+	doc             *html.Node
+	ids             map[string]*html.Node // Lazily built id index, see byID
+	labels          map[string]*html.Node // Lazily built label index, see labelFor
+	tableTitle      string                // Caption handed down from an enclosing figure
 	stripSet        map[string]bool
 	removeImgNoAlt  bool
+	skipHidden      bool
+	defense         DefenseMode
+	warnings        []Warning
+	showNoscript    bool
+	state           StateConfig
+	svgMode         SVGMode
+	notes           *footnotes                     // Footnote references and definitions, nil when there are none
+	abbrSeen        map[string]bool                // Abbreviations already expanded
+	widgets         *widgets                       // Tab and accordion panels, nil when there are none
+	slidesSeen      map[*html.Node]map[string]bool // Carousel slide texts per track
+	headings        HeadingConfig
+	headingState    headingState
+	anchorsUsed     map[string]bool
+	anchors         map[*html.Node]string // Anchor of each heading rendered, see headingAnchor
+	lastHeading     int                   // Level of the most recent heading
+	commentMode     CommentMode
+	comments        *commentGroups // Comment threads, nil when kept in place or absent
+	movedComments   []Node         // Threads built for the trailing section
+	inForm          bool           // Inside a <form>, whose controls are summarised
 	inPre           bool
 	listDepth       int
 	orderedListNums []int
@@ -538,6 +632,9 @@ func (ctx *mdContext) walk(n *html.Node) {
 }
 
 func (ctx *mdContext) renderText(text string) {
+	if ctx.defense != DefenseOff {
+		text = ctx.cleanInvisible(text)
+	}
 	if !ctx.inPre {
 		text = strings.TrimSpace(text)
 		if text == "" {
@@ -545,7 +642,7 @@ func (ctx *mdContext) renderText(text string) {
 		}
 		text = strings.ReplaceAll(text, "\n", " ")
 	}
-	ctx.buf.WriteString(text)
+	ctx.text(text)
 }
 
 func (ctx *mdContext) renderElement(n *html.Node) {
@@ -554,13 +651,76 @@ func (ctx *mdContext) renderElement(n *html.Node) {
 		return
 	}
 
+	// Widget panels are shown whether or not they are collapsed; only hiding
+	// beyond that keeps them out
+	panel := ctx.isPanel(n)
+
+	// Skip content the browser would not display
+	if ctx.skipHidden && !hasAttr(n, "data-llm", "keep") && !panel && isHidden(n) {
+		return
+	}
+
+	// Report (and strip or mark) text hidden from sighted readers
+	if ctx.defense != DefenseOff && !hasAttr(n, "data-llm", "keep") {
+		if reason := hiddenReason(n, panel); reason != "" && ctx.flagHidden(n, reason) {
+			return
+		}
+	}
+
+	// Comment threads are dropped, counted or moved per the comment mode
+	if ctx.comments != nil && ctx.renderComments(n) {
+		return
+	}
+
+	// Tab panels, accordion panels and carousel slides render even when collapsed
+	if ctx.renderWidget(n) {
+		return
+	}
+
+	// Question and answer pairs, including answers in closed <details>
+	if ctx.renderFAQ(n) {
+		return
+	}
+
+	// Render noscript fallback content when enabled or explicitly kept
+	if n.Data == "noscript" && (ctx.showNoscript || hasAttr(n, "data-llm", "keep")) {
+		ctx.renderNoscript(n)
+		return
+	}
+
+	// Framework state payloads become content when sections are configured
+	if n.Data == "script" && len(ctx.state.Sections) > 0 && ctx.renderState(n) {
+		return
+	}
+
+	// Footnote references become [^label]; definitions move to the end
+	if ctx.notes != nil {
+		if label, ok := ctx.notes.refs[n]; ok {
+			ctx.notes.used[label] = true
+			ctx.add(&FootnoteRef{Label: label})
+			return
+		}
+		if ctx.notes.skip[n] || isBacklink(n) {
+			return
+		}
+	}
+
+	// Formulas become LaTeX; KaTeX and MathJax presentation markup is skipped
+	if ctx.renderMathElement(n) {
+		return
+	}
+
+	// SVG is described from its title and labels when enabled
+	if n.Data == "svg" && ctx.svgMode != SVGDrop {
+		ctx.renderSVG(n)
+		return
+	}
+
 	// Check if should strip (unless data-llm="keep")
-	if ctx.stripSet[n.Data] && !hasAttr(n, "data-llm", "keep") {
+	if ctx.isStripped(n) {
 		if n.Data == "script" {
 			if desc := getAttr(n, "data-llm-description"); desc != "" {
-				ctx.buf.WriteString("\nJavascript description: ")
-				ctx.buf.WriteString(desc)
-				ctx.buf.WriteString("\n")
+				ctx.text("\nJavascript description: " + desc + "\n")
 			}
 		}
 		return
@@ -571,15 +731,24 @@ func (ctx *mdContext) renderElement(n *html.Node) {
 		return
 	}
 
+	// A form's controls are in its summary
+	if ctx.inForm && formControlTags[n.Data] {
+		return
+	}
+
 	// Check simple wrap rules first
-	if rule, ok := wrapRules[n.Data]; ok {
-		ctx.buf.WriteString(rule.prefix)
-		ctx.children(n)
-		ctx.buf.WriteString(rule.suffix)
+	if _, ok := wrapRules[n.Data]; ok {
+		ctx.add(ctx.wrapNode(n))
 		return
 	}
 
-	// Check pass-through tags
+	// Sectioning elements are kept as sections, other pass-through tags vanish
+	if sectioningTags[n.Data] {
+		section := &Section{ID: getAttr(n, "id")}
+		section.Children = ctx.build(func() { ctx.children(n) })
+		ctx.add(section)
+		return
+	}
 	if passThroughTags[n.Data] {
 		ctx.children(n)
 		return
@@ -587,10 +756,12 @@ func (ctx *mdContext) renderElement(n *html.Node) {
 
 	// Handle special cases
 	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		ctx.renderHeading(n)
 	case "br":
-		ctx.buf.WriteString("\n")
+		ctx.add(&LineBreak{})
 	case "hr":
-		ctx.buf.WriteString("\n---\n\n")
+		ctx.add(&ThematicBreak{})
 	case "code":
 		ctx.renderCode(n)
 	case "pre":
@@ -607,49 +778,212 @@ func (ctx *mdContext) renderElement(n *html.Node) {
 		ctx.renderListItem(n)
 	case "audio", "video":
 		ctx.renderMedia(n)
+	case "figure":
+		ctx.renderFigure(n)
+	case "table":
+		ctx.renderTable(n)
+	case "form":
+		ctx.renderForm(n)
+	case "button":
+		ctx.renderButton(n)
+	case "abbr", "acronym":
+		ctx.renderAbbr(n)
+	case "time":
+		ctx.renderValue(n, "datetime")
+	case "data":
+		ctx.renderValue(n, "value")
+	case "meter", "progress":
+		ctx.renderGauge(n)
 	default:
 		ctx.children(n)
 	}
 }
 
+// isStripped reports whether n is removed by the strip set, see shouldStrip
+func (ctx *mdContext) isStripped(n *html.Node) bool {
+	return shouldStrip(n, ctx.stripSet)
+}
+
+// hiddenReason is visuallyHidden as the walk applies it: widget panels are
+// shown whether or not they are collapsed, so only concealment keeps them out
+func hiddenReason(n *html.Node, panel bool) string {
+	if panel {
+		return concealed(n)
+	}
+	return visuallyHidden(n)
+}
+
+// excluded reports whether the walk leaves an element out: it is dropped,
+// stripped, hidden while hidden content is skipped, or hidden text the
+// defense strips or replaces with a marker
+func (ctx *mdContext) excluded(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if hasAttr(n, "data-llm", "drop") {
+		return true
+	}
+	panel := ctx.isPanel(n)
+	if ctx.isStripped(n) {
+		// Panels render despite aria-hidden, and noscript when its fallback is shown
+		if !(panel && !ctx.stripSet[effectiveTag(n)]) && !(n.Data == "noscript" && ctx.showNoscript) {
+			return true
+		}
+	}
+	if hasAttr(n, "data-llm", "keep") {
+		return false
+	}
+	if ctx.skipHidden && !panel && isHidden(n) {
+		return true
+	}
+	return ctx.defense != DefenseOff && hiddenReason(n, panel) != "" && textContent(n) != ""
+}
+
+// reachable reports whether the walk of the whole document renders n:
+// neither n nor any element around it is excluded
+func (ctx *mdContext) reachable(n *html.Node) bool {
+	for p := n; p != nil; p = p.Parent {
+		if ctx.excluded(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// wrapNode builds an element rendered by a wrap rule as its model node
+func (ctx *mdContext) wrapNode(n *html.Node) container {
+	var node container
+	switch n.Data {
+	case "p":
+		node = &Paragraph{}
+	case "blockquote", "address":
+		node = &Quote{}
+	case "caption":
+		node = &TableCaption{}
+	case "tr":
+		row := &TableRow{Header: n.Parent != nil && n.Parent.Data == "thead"}
+		row.Children = ctx.build(func() { ctx.children(n) })
+		if !row.Header {
+			row.Header = headerCells(row)
+		}
+		return row
+	case "th", "td":
+		node = &TableCell{Header: n.Data == "th"}
+	default:
+		node = &Format{Tag: n.Data}
+	}
+	*node.nodes() = ctx.build(func() { ctx.children(n) })
+	return node
+}
+
+// headerCells reports whether a row has cells and all of them are headers
+func headerCells(row *TableRow) bool {
+	found := false
+	for _, c := range row.Children {
+		if cell, ok := c.(*TableCell); ok {
+			if !cell.Header {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
 func (ctx *mdContext) renderCode(n *html.Node) {
 	if ctx.inPre {
 		ctx.children(n)
-	} else {
-		ctx.buf.WriteString("`")
-		ctx.children(n)
-		ctx.buf.WriteString("`")
+		return
 	}
+	code := &Code{}
+	code.Children = ctx.build(func() { ctx.children(n) })
+	ctx.add(code)
 }
 
 func (ctx *mdContext) renderPre(n *html.Node) {
-	ctx.buf.WriteString("\n```\n")
+	block := &CodeBlock{Lang: codeLanguage(n)}
 	ctx.inPre = true
-	ctx.children(n)
+	block.Children = ctx.build(func() { ctx.children(n) })
 	ctx.inPre = false
-	ctx.buf.WriteString("\n```\n\n")
+	ctx.add(block)
+}
+
+// codeLanguage returns the language of a <pre> from a language-* or lang-*
+// class on it or its <code> (the convention of highlight.js and Prism)
+func codeLanguage(pre *html.Node) string {
+	for _, n := range []*html.Node{pre, findChild(pre, "code")} {
+		if n == nil {
+			continue
+		}
+		for _, class := range strings.Fields(getAttr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
 }
 
+// renderLink builds a link. Links labelled with aria-labelledby/aria-label,
+// or with no visible text (icon links), use their accessible name instead.
 func (ctx *mdContext) renderLink(n *html.Node) {
-	ctx.buf.WriteString("[")
-	ctx.children(n)
-	ctx.buf.WriteString("](")
-	ctx.buf.WriteString(getAttr(n, "href"))
-	ctx.buf.WriteString(")")
+	href := strings.TrimSpace(getAttr(n, "href"))
+
+	var label []Node
+	if hasAttrKey(n, "aria-labelledby") || hasAttrKey(n, "aria-label") {
+		if name := ctx.accessibleName(n); name != "" {
+			label = []Node{&Text{Text: name}}
+		}
+	}
+	if label == nil {
+		label = ctx.build(func() { ctx.children(n) })
+		if strings.TrimSpace(ctx.md.render(label)) == "" {
+			label = nil
+		}
+	}
+	if label == nil {
+		if name := ctx.accessibleName(n); name != "" {
+			label = []Node{&Text{Text: name}}
+		}
+	}
+
+	if href == "" {
+		// Not a hyperlink (a placeholder or named anchor): keep any text
+		ctx.add(&Link{branch: branch{Children: label}})
+		return
+	}
+
+	if kind, value, ok := linkTarget(href); ok && value != "" {
+		if text := strings.TrimSpace(ctx.md.render(label)); text == "" || text == value {
+			ctx.text(kind + ": " + value)
+		} else {
+			ctx.add(&Link{branch: branch{Children: label}})
+			ctx.text(" (" + strings.ToLower(kind) + ": " + value + ")")
+		}
+		return
+	}
+
+	ctx.add(&Link{Href: href, branch: branch{Children: label}})
+}
+
+// renderButton writes a standalone button's accessible name as plain text
+func (ctx *mdContext) renderButton(n *html.Node) {
+	if name := ctx.accessibleName(n); name != "" {
+		ctx.text(" " + name + " ")
+	}
 }
 
 func (ctx *mdContext) renderImage(n *html.Node) {
-	alt := getAttr(n, "alt")
-	if alt == "" && ctx.removeImgNoAlt {
+	if isDecorativeImage(n) {
 		return
 	}
-	if alt != "" {
-		ctx.buf.WriteString("[Image: ")
-		ctx.buf.WriteString(alt)
-		ctx.buf.WriteString("]")
-	} else {
-		ctx.buf.WriteString("[Image]")
+	alt := ctx.accessibleName(n)
+	if alt == "" && ctx.removeImgNoAlt {
+		return
 	}
+	ctx.add(&Image{Alt: alt, Src: strings.TrimSpace(getAttr(n, "src"))})
 }
 
 func (ctx *mdContext) renderMedia(n *html.Node) {
@@ -661,46 +995,47 @@ func (ctx *mdContext) renderMedia(n *html.Node) {
 		mediaType = "Video"
 	}
 
-	src := getAttr(n, "src")
-	if src != "" {
-		ctx.buf.WriteString("[")
-		ctx.buf.WriteString(mediaType)
-		ctx.buf.WriteString(": ")
-		ctx.buf.WriteString(src)
-		ctx.buf.WriteString("]")
+	if src := getAttr(n, "src"); src != "" {
+		ctx.text("[" + mediaType + ": " + src + "]")
 	} else {
-		ctx.buf.WriteString("[")
-		ctx.buf.WriteString(mediaType)
-		ctx.buf.WriteString("]")
+		ctx.text("[" + mediaType + "]")
 	}
 	// Also render children (fallback content, source elements)
 	ctx.children(n)
 }
 
 func (ctx *mdContext) renderList(n *html.Node, ordered bool) {
-	ctx.buf.WriteString("\n")
+	list := &List{Ordered: ordered}
 	ctx.listDepth++
 	ctx.inOrderedList = append(ctx.inOrderedList, ordered)
 	if ordered && ctx.listDepth <= len(ctx.orderedListNums) {
 		ctx.orderedListNums[ctx.listDepth-1] = 0
 	}
-	ctx.children(n)
+	list.Children = ctx.build(func() { ctx.children(n) })
 	ctx.inOrderedList = ctx.inOrderedList[:len(ctx.inOrderedList)-1]
 	ctx.listDepth--
-	ctx.buf.WriteString("\n")
+	ctx.add(list)
 }
 
 func (ctx *mdContext) renderListItem(n *html.Node) {
-	ctx.buf.WriteString(strings.Repeat("  ", ctx.listDepth-1))
+	item := &ListItem{Depth: ctx.listDepth}
 	if len(ctx.inOrderedList) > 0 && ctx.inOrderedList[len(ctx.inOrderedList)-1] {
 		ctx.orderedListNums[ctx.listDepth-1]++
-		ctx.buf.WriteString(itoa(ctx.orderedListNums[ctx.listDepth-1]))
-		ctx.buf.WriteString(". ")
-	} else {
-		ctx.buf.WriteString("- ")
+		item.Number = ctx.orderedListNums[ctx.listDepth-1]
 	}
-	ctx.children(n)
-	ctx.buf.WriteString("\n")
+	item.Children = ctx.build(func() { ctx.children(n) })
+	ctx.add(item)
+}
+
+func (ctx *mdContext) renderTable(n *html.Node) {
+	table := &Table{}
+	if ctx.tableTitle != "" && findChild(n, "caption") == nil {
+		// A figure caption stands in for a missing <caption>
+		table.Title = ctx.tableTitle
+		ctx.tableTitle = ""
+	}
+	table.Children = ctx.build(func() { ctx.children(n) })
+	ctx.add(table)
 }
 
 func (ctx *mdContext) children(n *html.Node) {
@@ -709,6 +1044,73 @@ func (ctx *mdContext) children(n *html.Node) {
 	}
 }
 
+// add appends a node to the list being built
+func (ctx *mdContext) add(n Node) {
+	*ctx.out = append(*ctx.out, n)
+}
+
+// text appends literal text, extending a Text node just before it
+func (ctx *mdContext) text(s string) {
+	if s == "" {
+		return
+	}
+	if last := len(*ctx.out) - 1; last >= 0 {
+		if t, ok := (*ctx.out)[last].(*Text); ok {
+			t.Text += s
+			return
+		}
+	}
+	ctx.add(&Text{Text: s})
+}
+
+// build runs fn with a fresh node list and returns what it built
+func (ctx *mdContext) build(fn func()) []Node {
+	saved := ctx.out
+	var nodes []Node
+	ctx.out = &nodes
+	fn()
+	ctx.out = saved
+	return nodes
+}
+
+// byID returns the element with the given id, indexing the document on first use.
+func (ctx *mdContext) byID(id string) *html.Node {
+	if ctx.ids == nil {
+		ctx.ids = make(map[string]*html.Node)
+		var f func(*html.Node)
+		f = func(n *html.Node) {
+			if n.Type == html.ElementNode {
+				if id := getAttr(n, "id"); id != "" {
+					if _, dup := ctx.ids[id]; !dup {
+						ctx.ids[id] = n
+					}
+				}
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				f(c)
+			}
+		}
+		if ctx.doc != nil {
+			f(ctx.doc)
+		}
+	}
+	return ctx.ids[id]
+}
+
+// idrefText resolves a space-separated IDREF list (aria-labelledby, aria-describedby)
+// to the joined text content of the referenced elements.
+func (ctx *mdContext) idrefText(refs string) string {
+	var parts []string
+	for _, id := range strings.Fields(refs) {
+		if el := ctx.byID(id); el != nil {
+			if text := textContent(el); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 func getAttr(n *html.Node, key string) string {
 	for _, attr := range n.Attr {
 		if attr.Key == key {
@@ -718,6 +1120,68 @@ func getAttr(n *html.Node, key string) string {
 	return ""
 }
 
+// findChild returns the first direct element child with the given tag
+func findChild(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+// findDescendant returns the first element below n with the given tag
+func findDescendant(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+		if found := findDescendant(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent returns the whitespace-collapsed text of n and its descendants
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		block := n.Type == html.ElementNode && blockTags[n.Data]
+		if block {
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+		if block {
+			b.WriteByte(' ')
+		}
+	}
+	f(n)
+	return collapseSpace(b.String())
+}
+
+// collapseSpace trims s and folds every whitespace run into a single space
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// hasAttrKey reports whether n carries the attribute at all (boolean attributes)
+func hasAttrKey(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 func hasAttr(n *html.Node, key, val string) bool {
 	for _, attr := range n.Attr {
 		if attr.Key == key && attr.Val == val {
@@ -727,6 +1191,15 @@ func hasAttr(n *html.Node, key, val string) bool {
 	return false
 }
 
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(getAttr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
 func itoa(n int) string {
 	if n < 10 {
 		return string(rune('0' + n))
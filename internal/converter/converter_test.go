@@ -375,6 +375,52 @@ func TestHTMLToMarkdown_SkipTags(t *testing.T) {
 	}
 }
 
+func TestHTMLToMarkdown_LandmarkRoles(t *testing.T) {
+	input := []byte(`<html><body>
+		<div role="banner">Site banner</div>
+		<div role="navigation">Menu links</div>
+		<div role="search">Search box</div>
+		<div role="complementary">Related posts</div>
+		<header role="main"><h1>Article</h1></header>
+		<p>Body <span aria-hidden="true">decorative glyph</span>text</p>
+		<div role="contentinfo">Contact info</div>
+		<div role="navigation" data-llm="keep">Breadcrumbs</div>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	for _, dropped := range []string{"Site banner", "Menu links", "Search box", "Related posts", "decorative glyph", "Contact info"} {
+		if strings.Contains(result, dropped) {
+			t.Errorf("Result should not contain %q, got: %s", dropped, result)
+		}
+	}
+	for _, kept := range []string{"# Article", "Body", "Breadcrumbs"} {
+		if !strings.Contains(result, kept) {
+			t.Errorf("Result missing %q, got: %s", kept, result)
+		}
+	}
+}
+
+func TestProcessHTML_LandmarkRoles(t *testing.T) {
+	input := `<html><body>
+		<div role="navigation">Menu</div>
+		<div aria-hidden="true">Hidden</div>
+		<aside role="main">Main content</aside>
+	</body></html>`
+
+	result, err := ProcessHTML([]byte(input), StripConfig{})
+	if err != nil {
+		t.Fatalf("ProcessHTML failed: %v", err)
+	}
+
+	resultStr := string(result)
+	if strings.Contains(resultStr, "Menu") || strings.Contains(resultStr, "Hidden") {
+		t.Errorf("Landmark and aria-hidden content should be stripped, got: %s", resultStr)
+	}
+	if !strings.Contains(resultStr, "Main content") {
+		t.Error("role=main should be kept like <main>")
+	}
+}
+
 // =============================================================================
 // CondenseMarkdown Tests
 // =============================================================================
@@ -401,35 +447,36 @@ func TestCondenseMarkdown(t *testing.T) {
 	}
 }
 
-func TestCondenseMarkdown_RemovesNoise(t *testing.T) {
+func TestHTMLToMarkdown_RemovesNoise(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
 	}{
-		{"photo by", "Content\nPhoto by Someone\nMore content"},
-		{"copyright", "Content\nCopyright 2024\nMore content"},
-		{"credit", "Content\nCredit: Someone\nMore content"},
+		{"photo by", "<p>Content</p><p>Photo by Someone</p><p>More content</p>"},
+		{"copyright", "<p>Content</p><ul><li>Copyright 2024</li></ul><p>More content</p>"},
+		{"credit", "<p>Content<br>Credit: Someone</p><blockquote>More content</blockquote>"},
+		{"source", "<p>Content</p><blockquote><p>Source: Someone</p></blockquote><p>More content</p>"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CondenseMarkdown(tt.input)
+			result, _ := HTMLToMarkdown([]byte(tt.input), StripConfig{})
 			if strings.Contains(strings.ToLower(result), tt.name) {
-				t.Errorf("Result should not contain %q noise", tt.name)
+				t.Errorf("Result should not contain %q noise, got: %q", tt.name, result)
 			}
 			if !strings.Contains(result, "Content") || !strings.Contains(result, "More content") {
-				t.Error("Result missing actual content")
+				t.Errorf("Result missing actual content, got: %q", result)
 			}
 		})
 	}
 }
 
-func TestCondenseMarkdown_FixesFragmentedLists(t *testing.T) {
-	input := "1\n\nFirst item\n\n2\n\nSecond item"
-	result := CondenseMarkdown(input)
+func TestHTMLToMarkdown_FixesFragmentedLists(t *testing.T) {
+	input := []byte("<p>1</p><p>First item</p><p>2</p><p>Second item</p><p>in two parts</p><h2>Next</h2><p>3</p>")
+	result, _ := HTMLToMarkdown(input, StripConfig{})
 
-	if !strings.Contains(result, "1. First item") {
-		t.Errorf("Expected fixed list, got: %s", result)
+	if expected := "1. First item\n2. Second item - in two parts\n\n## Next\n\n3"; result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
 	}
 }
 
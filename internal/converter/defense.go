@@ -0,0 +1,383 @@
+package converter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// DefenseMode controls how content aimed at agents rather than readers is handled:
+// text a browser would not show, and instruction-like text that tries to steer an LLM.
+type DefenseMode int
+
+const (
+	DefenseOff   DefenseMode = iota // No detection (default)
+	DefenseFlag                     // Keep the content, marked up, and report it
+	DefenseStrip                    // Remove the content and report it
+)
+
+// Warning kinds
+const (
+	WarningHiddenText    = "hidden-text"
+	WarningInvisibleText = "invisible-characters"
+	WarningInstruction   = "suspicious-instruction"
+)
+
+// Cap on recorded warnings so a hostile page cannot bloat the report
+const maxWarnings = 50
+
+// Offending text is quoted up to this many bytes
+const maxWarningText = 120
+
+// Warning describes one piece of suspicious content found during conversion
+type Warning struct {
+	Kind   string // One of the Warning* kinds
+	Reason string // What triggered it, e.g. "font-size:0" or "ignore previous instructions"
+	Text   string // The offending text, truncated
+}
+
+func (w Warning) String() string {
+	return w.Kind + ": " + w.Reason
+}
+
+// Instruction-like phrases that have no business in page content an agent reads.
+// Each pattern is paired with the reason reported in the warning.
+var instructionPatterns = []struct {
+	re     *regexp.Regexp
+	reason string
+}{
+	{regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding|original)\s+(instructions|prompts?|directions|messages|rules)`), "ignore previous instructions"},
+	{regexp.MustCompile(`(?i)\b(new|updated|real|actual)\s+(system\s+)?instructions\s*:`), "replacement instructions"},
+	{regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\s+(your\s+|the\s+)(system\s+prompt|hidden\s+instructions|initial\s+prompt)`), "system prompt extraction"},
+	{regexp.MustCompile(`(?i)\bif\s+you\s+are\s+an?\s+(ai|llm|language\s+model|assistant|agent|chatbot)\b`), "addresses the AI reader"},
+	{regexp.MustCompile(`(?i)\b(do\s+not|don't|never)\s+(tell|inform|mention\s+(this\s+)?to|reveal\s+(this\s+)?to)\s+the\s+user`), "conceals from the user"},
+	{regexp.MustCompile(`(?i)\byou\s+are\s+now\s+((a|an|in)\s+)?(\w+\s+)?(ai|assistant|chatbot|llm|language\s+model|dan|developer\s+mode|jailbreak|unrestricted|unfiltered)\b`), "role reassignment"},
+	{regexp.MustCompile(`<\|im_start\|>|<\|im_end\|>|\[/?INST\]|<<SYS>>|<\|system\|>`), "chat template tokens"},
+}
+
+// visuallyHidden reports why a browser would not show n to a sighted reader,
+// or "" if it would. Beyond isHidden this covers the tricks used to smuggle text
+// past readers: zero font size, zero opacity, matching text and background
+// colours, off-screen positioning and zero-size boxes. Screen-reader-only
+// clipping (the sr-only pattern) is deliberately not treated as hidden.
+func visuallyHidden(n *html.Node) string {
+	if isHidden(n) {
+		return "not rendered"
+	}
+	return concealed(n)
+}
+
+// concealed reports the styling trick that keeps a rendered element from
+// sighted readers, or "" if there is none (see visuallyHidden)
+func concealed(n *html.Node) string {
+	style := inlineStyle(n)
+	if style == nil {
+		return ""
+	}
+
+	if size, ok := cssLength(style["font-size"]); ok && size <= 1 && !restoresFontSize(n) {
+		return "font-size:" + style["font-size"]
+	}
+	if opacity, err := strconv.ParseFloat(style["opacity"], 64); err == nil && opacity <= 0.01 {
+		return "opacity:" + style["opacity"]
+	}
+
+	if color := normalizeColor(style["color"]); color != "" {
+		if color == "transparent" {
+			return "transparent text"
+		}
+		if bg := backgroundColor(n); bg != "" && bg == color {
+			return "text colour matches background"
+		}
+	}
+
+	if pos := style["position"]; pos == "absolute" || pos == "fixed" {
+		for _, side := range []string{"left", "top", "right"} {
+			if offset, ok := cssLength(style[side]); ok && offset <= -500 {
+				return "positioned off-screen"
+			}
+		}
+	}
+	if indent, ok := cssLength(style["text-indent"]); ok && indent <= -500 {
+		return "text-indent off-screen"
+	}
+
+	width, wok := cssLength(style["width"])
+	height, hok := cssLength(style["height"])
+	if ((wok && width == 0) || (hok && height == 0)) && style["overflow"] == "hidden" {
+		return "zero-size box"
+	}
+	return ""
+}
+
+// restoresFontSize reports whether all the text under n, an element with a
+// zero font size, sits in descendants that set a visible size again: the
+// usual way to remove the whitespace between inline-block children
+func restoresFontSize(n *html.Node) bool {
+	restored := false
+	var f func(*html.Node) bool
+	f = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				if strings.TrimSpace(c.Data) != "" {
+					return false
+				}
+			case html.ElementNode:
+				if size, ok := cssLength(inlineStyle(c)["font-size"]); ok && size > 1 {
+					restored = true
+				} else if !f(c) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return f(n) && restored
+}
+
+// backgroundColor returns the nearest inline background colour on n or its ancestors
+func backgroundColor(n *html.Node) string {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode {
+			continue
+		}
+		style := inlineStyle(p)
+		if bg := normalizeColor(style["background-color"]); bg != "" {
+			return bg
+		}
+		// The background shorthand usually leads with the colour
+		if fields := strings.Fields(style["background"]); len(fields) > 0 {
+			if bg := normalizeColor(fields[0]); bg != "" {
+				return bg
+			}
+		}
+	}
+	return ""
+}
+
+var namedColors = map[string]string{
+	"white": "#ffffff", "black": "#000000", "red": "#ff0000", "green": "#008000",
+	"blue": "#0000ff", "gray": "#808080", "grey": "#808080", "yellow": "#ffff00",
+	"transparent": "transparent",
+}
+
+// normalizeColor folds hex, rgb()/rgba() and common named colours to #rrggbb,
+// returning "transparent" for fully transparent colours and "" for anything else.
+func normalizeColor(c string) string {
+	c = strings.ToLower(strings.ReplaceAll(c, " ", ""))
+	if named, ok := namedColors[c]; ok {
+		return named
+	}
+	if hex, ok := strings.CutPrefix(c, "#"); ok {
+		switch len(hex) {
+		case 3, 4:
+			if len(hex) == 4 && hex[3] == '0' {
+				return "transparent"
+			}
+			return "#" + string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		case 6, 8:
+			if len(hex) == 8 && hex[6:] == "00" {
+				return "transparent"
+			}
+			return "#" + hex[:6]
+		}
+		return ""
+	}
+	args, ok := strings.CutPrefix(c, "rgba(")
+	if !ok {
+		args, ok = strings.CutPrefix(c, "rgb(")
+	}
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	if len(parts) < 3 {
+		return ""
+	}
+	if len(parts) == 4 {
+		if alpha, err := strconv.ParseFloat(parts[3], 64); err == nil && alpha <= 0.01 {
+			return "transparent"
+		}
+	}
+	var b strings.Builder
+	b.WriteByte('#')
+	for _, p := range parts[:3] {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 || v > 255 {
+			return ""
+		}
+		b.WriteString(strconv.FormatInt(int64(v)/16, 16))
+		b.WriteString(strconv.FormatInt(int64(v)%16, 16))
+	}
+	return b.String()
+}
+
+// cssLength parses a CSS length in px (or unitless/em/rem/%, which are only
+// meaningful here when zero or negative) and reports whether it parsed.
+func cssLength(v string) (float64, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	for _, unit := range []string{"px", "rem", "em", "pt", "%"} {
+		if num, ok := strings.CutSuffix(v, unit); ok {
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, false
+			}
+			if unit != "px" && unit != "pt" && f > 0 {
+				// Relative sizes are only judged by sign; treat positive as visible
+				return 16, true
+			}
+			return f, true
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return f, err == nil
+}
+
+// flagHidden handles an element that is hidden from sighted readers. It reports
+// false when the element has no text worth reporting and should render normally.
+// Flagged text is cleaned of invisible characters like visible text; the
+// markdown it lands in is screened for instructions as a whole.
+func (ctx *mdContext) flagHidden(n *html.Node, reason string) bool {
+	text := collapseSpace(ctx.cleanInvisible(textContent(n)))
+	if text == "" {
+		return false
+	}
+	ctx.warn(Warning{Kind: WarningHiddenText, Reason: reason, Text: text})
+	if ctx.defense == DefenseFlag {
+		if blockTags[n.Data] {
+			ctx.text("\n[Hidden text: " + text + "]\n\n")
+		} else {
+			ctx.text(" [Hidden text: " + text + "] ")
+		}
+	}
+	return true
+}
+
+// cleanInvisible removes zero-width and Unicode tag characters from text. Tag
+// characters (U+E0000-U+E007F) mirror ASCII and are invisible in browsers, so
+// they are decoded into the warning to show what was being smuggled.
+func (ctx *mdContext) cleanInvisible(text string) string {
+	if !strings.ContainsFunc(text, isInvisibleRune) {
+		return text
+	}
+
+	var clean, smuggled strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= 0xE0000 && r <= 0xE007F:
+			if r >= 0xE0020 && r <= 0xE007E {
+				smuggled.WriteRune(r - 0xE0000)
+			}
+		case isInvisibleRune(r):
+		default:
+			clean.WriteRune(r)
+		}
+	}
+	if smuggled.Len() > 0 {
+		ctx.warn(Warning{Kind: WarningInvisibleText, Reason: "unicode tag characters", Text: smuggled.String()})
+	}
+	return clean.String()
+}
+
+func isInvisibleRune(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff', '\u202e', '\u202d':
+		return true
+	}
+	return r >= 0xE0000 && r <= 0xE007F
+}
+
+func (ctx *mdContext) warn(w Warning) {
+	if len(ctx.warnings) >= maxWarnings {
+		return
+	}
+	w.Text = truncateText(w.Text, maxWarningText)
+	ctx.warnings = append(ctx.warnings, w)
+}
+
+// screenInstructions looks for instruction-like sentences in converted markdown.
+// Matching sentences are reported and, in strip mode, removed; in flag mode the
+// line is prefixed with a marker instead.
+func screenInstructions(md string, mode DefenseMode) (string, []Warning) {
+	var warnings []Warning
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		reason := matchInstruction(line)
+		if reason == "" {
+			continue
+		}
+
+		if mode == DefenseFlag {
+			warnings = append(warnings, Warning{Kind: WarningInstruction, Reason: reason, Text: truncateText(strings.TrimSpace(line), maxWarningText)})
+			lines[i] = "[Suspicious instruction] " + line
+			continue
+		}
+
+		var kept []string
+		for _, sentence := range splitSentences(line) {
+			if r := matchInstruction(sentence); r != "" {
+				warnings = append(warnings, Warning{Kind: WarningInstruction, Reason: r, Text: truncateText(strings.TrimSpace(sentence), maxWarningText)})
+				continue
+			}
+			kept = append(kept, sentence)
+		}
+		lines[i] = strings.Join(kept, "")
+	}
+	if len(warnings) > maxWarnings {
+		warnings = warnings[:maxWarnings]
+	}
+	return strings.Join(lines, "\n"), warnings
+}
+
+func matchInstruction(s string) string {
+	for _, p := range instructionPatterns {
+		if p.re.MatchString(s) {
+			return p.reason
+		}
+	}
+	return ""
+}
+
+// splitSentences splits s after sentence-ending punctuation, keeping the
+// trailing whitespace with each sentence so the pieces rejoin exactly.
+func splitSentences(s string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '.' && s[i] != '!' && s[i] != '?' {
+			continue
+		}
+		j := i + 1
+		for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+			j++
+		}
+		if j > i+1 || j == len(s) {
+			sentences = append(sentences, s[start:j])
+			start = j
+			i = j - 1
+		}
+	}
+	if start < len(s) {
+		sentences = append(sentences, s[start:])
+	}
+	return sentences
+}
+
+// truncateText shortens s to at most max bytes on a rune boundary
+func truncateText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,133 @@
+package converter
+
+// Dialect selects the markdown syntax of the output
+type Dialect int
+
+const (
+	DialectExtended   Dialect = iota // CommonMark plus ==mark==, ^sup^, ~sub~, __ins__ and {#id} anchors (default)
+	DialectCommonMark                // Strict CommonMark; formatting it lacks becomes inline HTML, tables HTML blocks
+	DialectGFM                       // GitHub Flavored Markdown: pipe tables and ~~strikethrough~~
+	DialectPlain                     // Text without markup, link URLs or fences (see HTMLToText)
+)
+
+// Each dialect has a complete table of wrap rules, keyed like wrapRules (the
+// extended dialect's table); TestDialectRules_Complete checks the keys match.
+
+// CommonMark keeps formatting it has no syntax for as inline HTML
+var commonMarkRules = map[string]mdRule{
+	"p":          {"", "\n\n"},
+	"blockquote": {"\n> ", "\n\n"},
+	"address":    {"\n> ", "\n\n"},
+	"strong":     {" **", "** "},
+	"b":          {" **", "** "},
+	"em":         {" *", "* "},
+	"i":          {" *", "* "},
+	"u":          {" <u>", "</u> "},
+	"s":          {" <s>", "</s> "},
+	"del":        {" <del>", "</del> "},
+	"ins":        {" <ins>", "</ins> "},
+	"mark":       {" <mark>", "</mark> "},
+	"small":      {" ", " "},
+	"sub":        {"<sub>", "</sub>"},
+	"sup":        {"<sup>", "</sup>"},
+	"q":          {` "`, `" `},
+	"kbd":        {" `", "` "},
+	"samp":       {" `", "` "},
+	"var":        {" _", "_ "},
+	"dfn":        {" *", "* "},
+	"cite":       {" *", "* "},
+	"tr":         {"|", "\n"},
+	"th":         {" **", "** |"},
+	"td":         {" ", " |"},
+	"caption":    {"\n*", "*\n"},
+	"dl":         {"\n", "\n"},
+	"dt":         {"\n**", "**\n"},
+	"dd":         {": ", "\n"},
+	"details":    {"\n", "\n"},
+	"summary":    {"\n**", "**\n"},
+	"ruby":       {"", ""},
+	"rt":         {" (", ")"},
+	"rp":         {"", ""},
+}
+
+// GFM adds strikethrough to CommonMark; ~sub~ would read as strikethrough too
+var gfmRules = map[string]mdRule{
+	"p":          {"", "\n\n"},
+	"blockquote": {"\n> ", "\n\n"},
+	"address":    {"\n> ", "\n\n"},
+	"strong":     {" **", "** "},
+	"b":          {" **", "** "},
+	"em":         {" *", "* "},
+	"i":          {" *", "* "},
+	"u":          {" <u>", "</u> "},
+	"s":          {" ~~", "~~ "},
+	"del":        {" ~~", "~~ "},
+	"ins":        {" <ins>", "</ins> "},
+	"mark":       {" <mark>", "</mark> "},
+	"small":      {" ", " "},
+	"sub":        {"<sub>", "</sub>"},
+	"sup":        {"<sup>", "</sup>"},
+	"q":          {` "`, `" `},
+	"kbd":        {" `", "` "},
+	"samp":       {" `", "` "},
+	"var":        {" _", "_ "},
+	"dfn":        {" *", "* "},
+	"cite":       {" *", "* "},
+	"tr":         {"|", "\n"},
+	"th":         {" **", "** |"},
+	"td":         {" ", " |"},
+	"caption":    {"\n*", "*\n"},
+	"dl":         {"\n", "\n"},
+	"dt":         {"\n**", "**\n"},
+	"dd":         {": ", "\n"},
+	"details":    {"\n", "\n"},
+	"summary":    {"\n**", "**\n"},
+	"ruby":       {"", ""},
+	"rt":         {" (", ")"},
+	"rp":         {"", ""},
+}
+
+// Plain text keeps the spacing of inline elements but none of their markers
+var plainRules = map[string]mdRule{
+	"p":          {"", "\n\n"},
+	"blockquote": {"\n", "\n\n"},
+	"address":    {"\n", "\n\n"},
+	"strong":     {" ", " "},
+	"b":          {" ", " "},
+	"em":         {" ", " "},
+	"i":          {" ", " "},
+	"u":          {" ", " "},
+	"s":          {" ", " "},
+	"del":        {" ", " "},
+	"ins":        {" ", " "},
+	"mark":       {" ", " "},
+	"small":      {" ", " "},
+	"sub":        {"", ""},
+	"sup":        {"", ""},
+	"q":          {` "`, `" `},
+	"kbd":        {" ", " "},
+	"samp":       {" ", " "},
+	"var":        {" ", " "},
+	"dfn":        {" ", " "},
+	"cite":       {" ", " "},
+	"tr":         {"|", "\n"},
+	"th":         {" ", " |"},
+	"td":         {" ", " |"},
+	"caption":    {"\n", "\n"},
+	"dl":         {"\n", "\n"},
+	"dt":         {"\n", "\n"},
+	"dd":         {": ", "\n"},
+	"details":    {"\n", "\n"},
+	"summary":    {"\n", "\n"},
+	"ruby":       {"", ""},
+	"rt":         {" (", ")"},
+	"rp":         {"", ""},
+}
+
+// Wrap rules of each dialect
+var dialectRules = map[Dialect]map[string]mdRule{
+	DialectExtended:   wrapRules,
+	DialectCommonMark: commonMarkRules,
+	DialectGFM:        gfmRules,
+	DialectPlain:      plainRules,
+}
@@ -0,0 +1,250 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+const dialectPage = `<html><body>
+	<h2 id="intro">Intro</h2>
+	<p>Water is H<sub>2</sub>O, E=mc<sup>2</sup>. <mark>Note</mark> the <del>old</del> <ins>new</ins> <u>terms</u>, <strong>bold</strong> and <em>soft</em>.</p>
+	<p>See <a href="https://example.com/docs">the docs</a>.</p>
+	<hr>
+	<table>
+		<tr><th>Plan</th><th>Price</th></tr>
+		<tr><td>Basic | Lite</td><td>$10</td></tr>
+	</table>
+	<pre><code>go test ./...</code></pre>
+</body></html>`
+
+// parsed converts the page in a dialect and parses the result back
+func parsed(t *testing.T, dialect Dialect, md goldmark.Markdown) (string, ast.Node, []byte) {
+	t.Helper()
+	result, err := HTMLToMarkdown([]byte(dialectPage), StripConfig{Dialect: dialect, Anchors: AnchorsAttribute})
+	if err != nil {
+		t.Fatalf("HTMLToMarkdown failed: %v", err)
+	}
+	source := []byte(result)
+	return result, md.Parser().Parse(text.NewReader(source)), source
+}
+
+// nodes returns the nodes of a kind in document order
+func nodes(doc ast.Node, kind ast.NodeKind) []ast.Node {
+	var found []ast.Node
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Kind() == kind {
+			found = append(found, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// rawHTML returns the inline HTML tags of a document
+func rawHTML(doc ast.Node, source []byte) string {
+	var b strings.Builder
+	for _, n := range nodes(doc, ast.KindRawHTML) {
+		segs := n.(*ast.RawHTML).Segments
+		for i := 0; i < segs.Len(); i++ {
+			seg := segs.At(i)
+			b.Write(seg.Value(source))
+		}
+	}
+	return b.String()
+}
+
+var spaceBeforePunct = regexp.MustCompile(` [.,;:!?](\s|$)`)
+
+// checkSpacing checks that inline markup keeps the page's word spacing: one
+// space before the link, none doubled and none before punctuation
+func checkSpacing(t *testing.T, doc ast.Node, source []byte, result string) {
+	t.Helper()
+	links := nodes(doc, ast.KindLink)
+	if len(links) != 1 {
+		t.Fatalf("Expected one link, got: %s", result)
+	}
+	if prev, ok := links[0].PreviousSibling().(*ast.Text); !ok || string(prev.Segment.Value(source)) != "See " {
+		t.Errorf("Expected \"See \" before the link, got: %s", result)
+	}
+	if strings.Contains(result, "  ") || spaceBeforePunct.MatchString(result) {
+		t.Errorf("Expected single spaces and none before punctuation, got: %q", result)
+	}
+}
+
+func TestDialectRules_Complete(t *testing.T) {
+	for dialect, rules := range dialectRules {
+		for tag := range wrapRules {
+			if _, ok := rules[tag]; !ok {
+				t.Errorf("Dialect %d has no rule for <%s>", dialect, tag)
+			}
+		}
+		if len(rules) != len(wrapRules) {
+			t.Errorf("Dialect %d has %d rules, wrapRules has %d", dialect, len(rules), len(wrapRules))
+		}
+	}
+	if len(dialectRules) != int(DialectPlain)+1 {
+		t.Errorf("Expected a rule table for every dialect, got %d", len(dialectRules))
+	}
+}
+
+func TestDialect_CommonMark(t *testing.T) {
+	result, doc, source := parsed(t, DialectCommonMark, goldmark.New())
+
+	headings := nodes(doc, ast.KindHeading)
+	if len(headings) != 1 || headings[0].(*ast.Heading).Level != 2 {
+		t.Errorf("Expected one level 2 heading, got %d in: %s", len(headings), result)
+	}
+	if !strings.Contains(result, `## <a id="intro"></a>Intro`) {
+		t.Errorf("Expected HTML anchor instead of {#id}, got: %s", result)
+	}
+	if len(nodes(doc, ast.KindThematicBreak)) != 1 {
+		t.Errorf("Expected a thematic break, got: %s", result)
+	}
+	if len(nodes(doc, ast.KindFencedCodeBlock)) != 1 {
+		t.Errorf("Expected a code block, got: %s", result)
+	}
+	checkSpacing(t, doc, source, result)
+
+	// strong and em are native; the rest is inline HTML rather than == ^ ~ __
+	if len(nodes(doc, ast.KindEmphasis)) != 2 {
+		t.Errorf("Expected only <strong> and <em> as emphasis, got: %s", result)
+	}
+	html := rawHTML(doc, source)
+	for _, tag := range []string{"<sub>", "<sup>", "<mark>", "<del>", "<ins>", "<u>"} {
+		if !strings.Contains(html, tag) {
+			t.Errorf("Expected %s as inline HTML, got: %s", tag, result)
+		}
+	}
+	for _, marker := range []string{"==", "^", "~", "__"} {
+		if strings.Contains(result, marker) {
+			t.Errorf("CommonMark output should not contain %q: %s", marker, result)
+		}
+	}
+
+	blocks := nodes(doc, ast.KindHTMLBlock)
+	if len(blocks) != 1 || !strings.Contains(result, "<tr><th>Plan</th><th>Price</th></tr>\n<tr><td>Basic | Lite</td><td>$10</td></tr>") {
+		t.Errorf("Expected the table as one HTML block, got: %s", result)
+	}
+}
+
+func TestDialect_GFM(t *testing.T) {
+	result, doc, source := parsed(t, DialectGFM, goldmark.New(goldmark.WithExtensions(extension.GFM)))
+
+	tables := nodes(doc, extast.KindTable)
+	if len(tables) != 1 {
+		t.Fatalf("Expected a GFM table, got: %s", result)
+	}
+	var cells []string
+	for _, cell := range nodes(tables[0], extast.KindTableCell) {
+		cells = append(cells, strings.ReplaceAll(string(cell.Lines().Value(source)), `\|`, "|"))
+	}
+	if got := strings.Join(cells, ","); got != "Plan,Price,Basic | Lite,$10" {
+		t.Errorf("Expected cells Plan,Price,Basic | Lite,$10, got %q in: %s", got, result)
+	}
+	if len(nodes(tables[0], extast.KindTableHeader)) != 1 {
+		t.Errorf("Expected a header row, got: %s", result)
+	}
+
+	checkSpacing(t, doc, source, result)
+	if len(nodes(doc, extast.KindStrikethrough)) != 1 {
+		t.Errorf("Expected <del> as strikethrough, got: %s", result)
+	}
+	html := rawHTML(doc, source)
+	for _, tag := range []string{"<sub>", "<sup>", "<mark>", "<ins>", "<u>"} {
+		if !strings.Contains(html, tag) {
+			t.Errorf("Expected %s as inline HTML, got: %s", tag, result)
+		}
+	}
+}
+
+func TestDialect_Plain(t *testing.T) {
+	result, err := HTMLToMarkdown([]byte(dialectPage), StripConfig{Dialect: DialectPlain, Anchors: AnchorsAttribute})
+	if err != nil {
+		t.Fatalf("HTMLToMarkdown failed: %v", err)
+	}
+	expected := "Intro\n\nWater is H2O, E=mc2. Note the old new terms, bold and soft.\n\n" +
+		"See the docs.\n\n" +
+		"Plan | Price\n\"Basic | Lite\" | $10\n\ngo test ./..."
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestDialect_Footnotes(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>Claim<sup><a href="#fn1" id="ref1">1</a></sup></p>
+		<ol class="footnotes"><li id="fn1">Source. <a href="#ref1">↩</a></li></ol>
+	</body></html>`)
+
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{DialectExtended, "Claim[^1]\n\n[^1]: Source."},
+		{DialectGFM, "Claim[^1]\n\n[^1]: Source."},
+		{DialectCommonMark, "Claim[1]\n\n[1] Source."},
+	}
+	for _, tt := range tests {
+		result, err := HTMLToMarkdown(input, StripConfig{Dialect: tt.dialect})
+		if err != nil {
+			t.Fatalf("HTMLToMarkdown failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("Dialect %d: expected %q, got: %q", tt.dialect, tt.expected, result)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	input := []byte(`<html><body><main>
+		<h1 id="guide">Guide</h1>
+		<p>Read the <a href="/docs">install docs</a> <strong>before</strong> you start.</p>
+		<p><img src="/flow.png" alt="Install flow"></p>
+		<ul><li>Download<ul><li>Linux</li></ul></li><li>Run <code>setup</code> as root</li></ul>
+		<ol><li>First</li><li>Second</li></ol>
+		<blockquote><p>Quoted text</p></blockquote>
+		<hr>
+		<pre><code class="language-sh">make install</code></pre>
+	</main></body></html>`)
+
+	// Dialect and anchors are ignored: plain text has neither
+	result, err := HTMLToText(input, StripConfig{Dialect: DialectGFM, Anchors: AnchorsAttribute})
+	if err != nil {
+		t.Fatalf("HTMLToText failed: %v", err)
+	}
+	expected := "Guide\n\nRead the install docs before you start.\n\nInstall flow\n\n" +
+		"- Download\n  - Linux\n\n- Run setup as root\n\n1. First\n2. Second\n\n" +
+		"Quoted text\n\nmake install"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+
+	markdown, _ := HTMLToMarkdown(input, StripConfig{})
+	if estimateTokens(result) >= estimateTokens(markdown) {
+		t.Errorf("Expected text (~%d tokens) to be smaller than markdown (~%d tokens)",
+			estimateTokens(result), estimateTokens(markdown))
+	}
+}
+
+func TestHTMLToText_Punctuation(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>See <a href="/docs">the docs</a>. Run <code>go test</code>, then <em>ship</em>!</p>
+		<p>Options (<a href="/a">first</a> or <kbd>Ctrl</kbd>) are <b>bold</b>; <i>a</i><i>b</i> stay apart.</p>
+	</body></html>`)
+
+	result, err := HTMLToText(input, StripConfig{})
+	if err != nil {
+		t.Fatalf("HTMLToText failed: %v", err)
+	}
+	expected := "See the docs. Run go test, then ship!\n\nOptions (first or Ctrl) are bold; a b stay apart."
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
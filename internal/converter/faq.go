@@ -0,0 +1,172 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// FAQ is a question and answer pair found on the page. The answer is markdown.
+type FAQ struct {
+	Question string
+	Answer   string
+}
+
+// renderFAQ renders question and answer markup as
+//
+//	Q: question
+//	A: answer
+//
+// and records the pair. It recognises schema.org Question microdata, <details>
+// whose summary is a question (or that sit in an FAQ section) and definition
+// lists of questions. It reports whether n was consumed.
+func (ctx *mdContext) renderFAQ(n *html.Node) bool {
+	if ctx.isStripped(n) {
+		return false
+	}
+	switch {
+	case hasItemType(n, "Question"):
+		q := findItemProp(n, "name")
+		a := findItemProp(n, "acceptedAnswer")
+		if q == nil || a == nil {
+			return false
+		}
+		if text := findItemProp(a, "text"); text != nil {
+			a = text
+		}
+		ctx.writeQA(textContent(q), ctx.build(func() { ctx.children(a) }))
+		return true
+
+	case n.Data == "details":
+		summary := findChild(n, "summary")
+		if summary == nil {
+			return false
+		}
+		q := textContent(summary)
+		if !isQuestion(q) && !inFAQSection(n) {
+			return false
+		}
+		answer := ctx.build(func() {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c != summary {
+					ctx.walk(c)
+				}
+			}
+		})
+		ctx.writeQA(q, answer)
+		return true
+
+	case n.Data == "dl":
+		items := definitionItems(n)
+		if len(items) == 0 {
+			return false
+		}
+		inSection := inFAQSection(n)
+		for _, item := range items {
+			if item.Data == "dt" && !inSection && !isQuestion(textContent(item)) {
+				return false
+			}
+		}
+		for i, item := range items {
+			if item.Data != "dt" {
+				continue
+			}
+			var answer []Node
+			for _, dd := range items[i+1:] {
+				if dd.Data != "dd" {
+					break
+				}
+				answer = append(answer, ctx.build(func() { ctx.children(dd) })...)
+				answer = append(answer, &Text{Text: "\n\n"})
+			}
+			ctx.writeQA(textContent(item), answer)
+		}
+		return true
+	}
+	return false
+}
+
+// writeQA adds one pair
+func (ctx *mdContext) writeQA(question string, answer []Node) {
+	if question != "" {
+		ctx.add(&QA{Question: question, branch: branch{Children: answer}})
+	}
+}
+
+// faq returns the question and answer pairs of a document, in order
+func (r *markdownRenderer) faq(doc *Document) []FAQ {
+	var faq []FAQ
+	walkModel(doc, func(n Node) bool {
+		if qa, ok := n.(*QA); ok {
+			faq = append(faq, FAQ{Question: qa.Question, Answer: r.answer(qa)})
+		}
+		return true
+	})
+	return faq
+}
+
+// definitionItems returns the dt and dd elements of a list, looking through
+// the <div> groups HTML allows inside <dl>
+func definitionItems(dl *html.Node) []*html.Node {
+	var items []*html.Node
+	for c := dl.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "dt", "dd":
+			items = append(items, c)
+		case "div":
+			items = append(items, definitionItems(c)...)
+		}
+	}
+	return items
+}
+
+func isQuestion(s string) bool {
+	return strings.HasSuffix(s, "?") || strings.HasSuffix(s, "？")
+}
+
+// inFAQSection reports whether n sits in FAQPage microdata or in an element
+// whose id or class names an FAQ
+func inFAQSection(n *html.Node) bool {
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if hasItemType(p, "FAQPage") {
+			return true
+		}
+		for _, name := range append(strings.Fields(getAttr(p, "class")), getAttr(p, "id")) {
+			if strings.Contains(strings.ToLower(name), "faq") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasItemType reports whether n is a schema.org item of the given type
+func hasItemType(n *html.Node, typ string) bool {
+	for _, t := range strings.Fields(getAttr(n, "itemtype")) {
+		if strings.HasSuffix(t, "schema.org/"+typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// findItemProp returns the first descendant carrying itemprop=prop
+func findItemProp(n *html.Node, prop string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, p := range strings.Fields(getAttr(c, "itemprop")) {
+			if p == prop {
+				return c
+			}
+		}
+		if found := findItemProp(c, prop); found != nil {
+			return found
+		}
+	}
+	return nil
+}
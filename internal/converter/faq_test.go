@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvert_FAQ(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []FAQ
+	}{
+		{
+			"details with questions",
+			`<details><summary>How do I reset my password?</summary><p>Use the <strong>Forgot password</strong> link.</p></details>
+			<details open><summary>Do you ship abroad?</summary><p>Yes.</p><p>To 40 countries.</p></details>
+			<details><summary>Changelog</summary><p>v2</p></details>`,
+			[]FAQ{
+				{"How do I reset my password?", "Use the **Forgot password** link."},
+				{"Do you ship abroad?", "Yes.\n\nTo 40 countries."},
+			},
+		},
+		{
+			"details in faq section",
+			`<section id="faq"><details><summary>Pricing</summary><p>Free for small teams.</p></details></section>`,
+			[]FAQ{{"Pricing", "Free for small teams."}},
+		},
+		{
+			"FAQPage microdata",
+			`<div itemscope itemtype="https://schema.org/FAQPage">
+				<div itemscope itemprop="mainEntity" itemtype="https://schema.org/Question">
+					<h3 itemprop="name">Is there a free trial?</h3>
+					<div itemscope itemprop="acceptedAnswer" itemtype="https://schema.org/Answer">
+						<div itemprop="text"><p>Yes, 14 days.</p></div>
+					</div>
+				</div>
+			</div>`,
+			[]FAQ{{"Is there a free trial?", "Yes, 14 days."}},
+		},
+		{
+			"definition list",
+			`<dl><dt>Can I cancel?</dt><dd>Any time.</dd><div><dt>Is data exported?</dt><dd>As CSV.</dd><dd>Or JSON.</dd></div></dl>`,
+			[]FAQ{{"Can I cancel?", "Any time."}, {"Is data exported?", "As CSV.\n\nOr JSON."}},
+		},
+		{
+			"glossary is not an FAQ",
+			`<dl><dt>API</dt><dd>Application interface</dd></dl>`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert([]byte("<html><body>"+tt.input+"</body></html>"), StripConfig{SkipHidden: true})
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if !reflect.DeepEqual(result.FAQ, tt.expected) {
+				t.Errorf("Expected %q, got: %q", tt.expected, result.FAQ)
+			}
+			for _, qa := range tt.expected {
+				if !strings.Contains(result.Markdown, "Q: "+qa.Question+"\nA: "+qa.Answer) {
+					t.Errorf("Expected Q/A for %q in markdown, got: %q", qa.Question, result.Markdown)
+				}
+			}
+		})
+	}
+}
+
+func TestConvert_FAQHidden(t *testing.T) {
+	input := []byte(`<html><body>
+		<details style="display:none"><summary>Is it free?</summary><p>Yes.</p></details>
+		<div itemscope itemtype="https://schema.org/Question" style="opacity:0">
+			<span itemprop="name">Is it safe?</span>
+			<div itemprop="acceptedAnswer" itemscope itemtype="https://schema.org/Answer"><span itemprop="text">Trust us.</span></div>
+		</div>
+	</body></html>`)
+	result, _ := Convert(input, StripConfig{SkipHidden: true, Defense: DefenseStrip})
+
+	if result.Markdown != "" || len(result.FAQ) != 0 {
+		t.Errorf("Expected hidden questions left out, got: %q %v", result.Markdown, result.FAQ)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Expected one hidden-text warning, got: %v", result.Warnings)
+	}
+}
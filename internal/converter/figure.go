@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// renderFigure renders a <figure> as one unit: the figure content followed by an
+// italic "Figure: caption" line. A table in the figure without a <caption> of
+// its own takes the figure's caption as its title instead.
+func (ctx *mdContext) renderFigure(n *html.Node) {
+	caption := ctx.figureCaption(n)
+
+	// renderTable clears the title when it takes it
+	saved := ctx.tableTitle
+	ctx.tableTitle = caption
+
+	figure := &Figure{}
+	figure.Children = ctx.build(func() {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "figcaption" {
+				continue
+			}
+			ctx.walk(c)
+		}
+	})
+
+	// No table rendered to take the caption: it stays the figure's
+	figure.Caption = ctx.tableTitle
+	ctx.tableTitle = saved
+	ctx.add(figure)
+}
+
+// figureCaption picks the best caption for a figure, in order of preference:
+// a <figcaption>, aria-describedby on the figure or its image, then the image's
+// longdesc (an in-page fragment is resolved to its text, anything else is linked).
+func (ctx *mdContext) figureCaption(n *html.Node) string {
+	if fc := findChild(n, "figcaption"); fc != nil {
+		if caption := textContent(fc); caption != "" {
+			return caption
+		}
+	}
+
+	if caption := ctx.idrefText(getAttr(n, "aria-describedby")); caption != "" {
+		return caption
+	}
+
+	img := findDescendant(n, "img")
+	if img == nil {
+		return ""
+	}
+	if caption := ctx.idrefText(getAttr(img, "aria-describedby")); caption != "" {
+		return caption
+	}
+
+	longdesc := strings.TrimSpace(getAttr(img, "longdesc"))
+	if longdesc == "" {
+		return ""
+	}
+	if id, ok := strings.CutPrefix(longdesc, "#"); ok {
+		return ctx.idrefText(id)
+	}
+	return "[Description](" + longdesc + ")"
+}
@@ -0,0 +1,141 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_FigureCaption(t *testing.T) {
+	input := []byte(`<html><body>
+		<figure>
+			<img src="chart.png" alt="Revenue chart">
+			<figcaption>Revenue by <em>quarter</em></figcaption>
+		</figure>
+		<p>Next paragraph</p>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	expected := "[Image: Revenue chart]\n*Figure: Revenue by quarter*"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected %q, got: %s", expected, result)
+	}
+	if !strings.Contains(result, "*\n\nNext paragraph") {
+		t.Errorf("Caption should be separated from the next paragraph, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_FigureCodeListing(t *testing.T) {
+	input := []byte(`<html><body>
+		<figure>
+			<figcaption>Listing 1</figcaption>
+			<pre><code>go run .</code></pre>
+		</figure>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	codeIdx := strings.Index(result, "go run .")
+	captionIdx := strings.Index(result, "*Figure: Listing 1*")
+	if codeIdx < 0 || captionIdx < 0 || captionIdx < codeIdx {
+		t.Errorf("Expected caption after code listing, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_FigureDescribedBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		figure   string
+		expected string
+	}{
+		{
+			"aria-describedby on figure",
+			`<figure aria-describedby="d1"><img src="a.png" alt="A"></figure>`,
+			"*Figure: Detailed description*",
+		},
+		{
+			"aria-describedby on image",
+			`<figure><img src="a.png" alt="A" aria-describedby="d1"></figure>`,
+			"*Figure: Detailed description*",
+		},
+		{
+			"longdesc fragment",
+			`<figure><img src="a.png" alt="A" longdesc="#d1"></figure>`,
+			"*Figure: Detailed description*",
+		},
+		{
+			"longdesc url",
+			`<figure><img src="a.png" alt="A" longdesc="/desc.html"></figure>`,
+			"*Figure: [Description](/desc.html)*",
+		},
+		{
+			"figcaption wins",
+			`<figure aria-describedby="d1"><img src="a.png" alt="A"><figcaption>Own caption</figcaption></figure>`,
+			"*Figure: Own caption*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte(`<html><body>` + tt.figure + `<p id="d1">Detailed description</p></body></html>`)
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected %q, got: %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_FigureTable(t *testing.T) {
+	input := []byte(`<html><body>
+		<figure>
+			<figcaption>Pricing tiers</figcaption>
+			<table><tr><th>Plan</th></tr><tr><td>Pro</td></tr></table>
+		</figure>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	titleIdx := strings.Index(result, "*Pricing tiers*")
+	tableIdx := strings.Index(result, "| **Plan**")
+	if titleIdx < 0 || tableIdx < 0 || titleIdx > tableIdx {
+		t.Errorf("Expected caption as table title, got: %s", result)
+	}
+	if strings.Contains(result, "Figure:") {
+		t.Errorf("Table figure should not get a Figure line, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_FigureTableKeepsCaption(t *testing.T) {
+	tests := []struct {
+		name, table, expected string
+	}{
+		{"table caption", `<table><caption>TabCap</caption><tr><td>Pro</td></tr></table>`, "*TabCap*\n| Pro |\n\n*Figure: FigCap*"},
+		{"dropped table", `<table data-llm="drop"><tr><td>Pro</td></tr></table>`, "*Figure: FigCap*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte(`<html><body><figure><figcaption>FigCap</figcaption>` + tt.table + `</figure></body></html>`)
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_FigureAfterText(t *testing.T) {
+	input := []byte(`<html><body>Some text <figure><img src="a.png" alt="A"><figcaption>Cap</figcaption></figure></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if expected := "Some text\n\n[Image: A]\n*Figure: Cap*"; result != expected {
+		t.Errorf("Expected the figure as its own block, got: %q", result)
+	}
+}
+
+func TestHTMLToMarkdown_FigureNoCaption(t *testing.T) {
+	input := []byte(`<html><body><figure><img src="a.png" alt="Alone"></figure></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if result != "[Image: Alone]" {
+		t.Errorf("Expected bare image, got: %q", result)
+	}
+}
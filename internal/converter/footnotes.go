@@ -0,0 +1,196 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Class names and roles that mark footnote markup (Wikipedia, Pandoc,
+// markdown-it, DPUB-ARIA)
+var (
+	footnoteRefClasses       = []string{"footnote-ref", "reference", "footnote-reference", "noteref"}
+	footnoteContainerClasses = []string{"footnotes", "references", "reflist", "footnote-list", "endnotes"}
+	footnoteBacklinkClasses  = []string{"footnote-backref", "footnote-back", "mw-cite-backlink", "reversefootnote"}
+	footnoteContainerRoles   = map[string]bool{"doc-endnotes": true, "doc-footnotes": true}
+)
+
+// Link texts used for "back to reference" arrows
+var backlinkTexts = map[string]bool{"↩": true, "↩\ufe0e": true, "↩\ufe0f": true, "↑": true, "^": true}
+
+type footnote struct {
+	label  string
+	target *html.Node
+}
+
+// footnotes records the footnote references of a document and the elements
+// holding their definitions. References render as [^label], definitions are
+// moved to a block at the end.
+type footnotes struct {
+	refs  map[*html.Node]string // Reference element (the <sup>, or the link itself) -> label
+	skip  map[*html.Node]bool   // Definitions and footnote lists left out of the flow
+	notes []footnote            // Definitions in order of first reference
+	used  map[string]bool       // Labels referenced in the rendered output
+}
+
+// collectFootnotes finds links to in-page footnote definitions. It returns nil
+// when the document has none.
+func (ctx *mdContext) collectFootnotes() *footnotes {
+	fn := &footnotes{
+		refs: make(map[*html.Node]string),
+		skip: make(map[*html.Node]bool),
+		used: make(map[string]bool),
+	}
+	labels := make(map[*html.Node]string)
+	used := make(map[string]bool)
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.Data == "a" && isFootnoteRef(c) {
+				if target := ctx.footnoteTarget(c); target != nil {
+					label, seen := labels[target]
+					if !seen {
+						label = footnoteLabel(textContent(c))
+						if label == "" || used[label] {
+							label = itoa(len(fn.notes) + 1)
+						}
+						labels[target] = label
+						used[label] = true
+						fn.notes = append(fn.notes, footnote{label: label, target: target})
+					}
+					ref := c
+					if c.Parent != nil && c.Parent.Data == "sup" && textContent(c.Parent) == textContent(c) {
+						ref = c.Parent
+					}
+					fn.refs[ref] = label
+					continue
+				}
+			}
+			f(c)
+		}
+	}
+	f(ctx.doc)
+
+	if len(fn.notes) == 0 {
+		return nil
+	}
+	for _, note := range fn.notes {
+		fn.skip[footnoteContainer(note.target, labels)] = true
+	}
+	return fn
+}
+
+// isFootnoteRef reports whether a link is marked up as a footnote reference
+func isFootnoteRef(a *html.Node) bool {
+	if !strings.HasPrefix(getAttr(a, "href"), "#") {
+		return false
+	}
+	if hasAttr(a, "role", "doc-noteref") || hasAnyClass(a, footnoteRefClasses) {
+		return true
+	}
+	p := a.Parent
+	return p != nil && p.Type == html.ElementNode && p.Data == "sup"
+}
+
+// footnoteTarget resolves a reference to the element holding the definition.
+// A bare anchor (<a id="fn1"></a>) stands for its parent.
+func (ctx *mdContext) footnoteTarget(a *html.Node) *html.Node {
+	target := ctx.byID(strings.TrimPrefix(getAttr(a, "href"), "#"))
+	if target == nil || target == a {
+		return nil
+	}
+	if textContent(target) == "" && target.Parent != nil {
+		target = target.Parent
+	}
+	// Links into headings are section links, not footnotes
+	if textContent(target) == "" || isAncestor(target, a) || headingTags[target.Data] {
+		return nil
+	}
+	return target
+}
+
+// footnoteContainer returns what to leave out of the flow for a definition:
+// the enclosing footnotes section, the list when every item is a definition,
+// or else the definition itself.
+func footnoteContainer(target *html.Node, labels map[*html.Node]string) *html.Node {
+	for p := target.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if footnoteContainerRoles[getAttr(p, "role")] || hasAnyClass(p, footnoteContainerClasses) {
+			return p
+		}
+	}
+
+	list := target.Parent
+	if target.Data != "li" || list == nil || (list.Data != "ol" && list.Data != "ul") {
+		return target
+	}
+	for c := list.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "li" {
+			if _, ok := labels[c]; !ok {
+				return target
+			}
+		}
+	}
+	return list
+}
+
+// footnoteLabel turns reference text ("[1]", "a", "note 3") into a label,
+// or "" when it is not usable as one
+func footnoteLabel(text string) string {
+	text = strings.Trim(text, "[]() ")
+	if text == "" {
+		return ""
+	}
+	for _, r := range text {
+		if !(r == '-' || r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')) {
+			return ""
+		}
+	}
+	return text
+}
+
+// isBacklink reports whether n is a "back to reference" link in a definition
+func isBacklink(n *html.Node) bool {
+	if hasAttr(n, "role", "doc-backlink") || hasAnyClass(n, footnoteBacklinkClasses) {
+		return true
+	}
+	return n.Data == "a" && strings.HasPrefix(getAttr(n, "href"), "#") && backlinkTexts[textContent(n)]
+}
+
+// renderFootnotes adds the definitions block with the notes referenced in the output
+func (ctx *mdContext) renderFootnotes() {
+	if ctx.notes == nil {
+		return
+	}
+	block := &Footnotes{}
+	for _, note := range ctx.notes.notes {
+		if !ctx.notes.used[note.label] {
+			continue
+		}
+		def := &Footnote{Label: note.label}
+		def.Children = ctx.build(func() { ctx.children(note.target) })
+		block.Children = append(block.Children, def)
+	}
+	ctx.add(block)
+}
+
+func hasAnyClass(n *html.Node, classes []string) bool {
+	for _, class := range classes {
+		if hasClass(n, class) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAncestor(ancestor, n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
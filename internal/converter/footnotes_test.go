@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_Footnotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"wikipedia",
+			`<p>Paris is the capital.<sup id="cite_ref-1" class="reference"><a href="#cite_note-1">[1]</a></sup> It is large.<sup class="reference"><a href="#cite_note-2">[2]</a></sup></p>
+			<h2>References</h2>
+			<div class="reflist"><ol class="references">
+				<li id="cite_note-1"><span class="mw-cite-backlink"><b><a href="#cite_ref-1">^</a></b></span> <span class="reference-text">Atlas of France.</span></li>
+				<li id="cite_note-2"><span class="mw-cite-backlink"><b><a href="#cite_ref-2">^</a></b></span> <span class="reference-text">City census.</span></li>
+			</ol></div>`,
+			"Paris is the capital.[^1] It is large.[^2]\n\n## References\n\n[^1]: Atlas of France.\n[^2]: City census.",
+		},
+		{
+			"pandoc",
+			`<p>Claim<a href="#fn1" class="footnote-ref" id="fnref1" role="doc-noteref"><sup>1</sup></a> and again<a href="#fn1" class="footnote-ref" role="doc-noteref"><sup>1</sup></a>.</p>
+			<section class="footnotes" role="doc-endnotes"><hr><ol>
+				<li id="fn1"><p>The <em>source</em> text.<a href="#fnref1" class="footnote-back" role="doc-backlink">↩︎</a></p></li>
+			</ol></section>`,
+			"Claim[^1] and again[^1].\n\n[^1]: The *source* text.",
+		},
+		{
+			"plain list of notes",
+			`<p>One<sup><a href="#n1">a</a></sup> two<sup><a href="#n2">*</a></sup></p>
+			<ol><li id="n1">First note <a href="#r1">↩</a></li><li id="n2">Second note</li></ol>`,
+			"One[^a] two[^2]\n\n[^a]: First note\n[^2]: Second note",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := HTMLToMarkdown([]byte("<html><body>"+tt.input+"</body></html>"), StripConfig{})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_SupLinkNotFootnote(t *testing.T) {
+	input := []byte(`<html><body><p>See<sup><a href="#setup">setup</a></sup></p><h2 id="setup">Setup</h2><p>Steps</p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if strings.Contains(result, "[^") || !strings.Contains(result, "## Setup") {
+		t.Errorf("Links to headings should not become footnotes, got: %q", result)
+	}
+}
@@ -0,0 +1,333 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Options beyond this count are summarised as "(N more)" to keep long
+// country/state pickers from dominating the output.
+const maxFormOptions = 15
+
+// Controls left out when a form's content renders: the summary lists them
+var formControlTags = map[string]bool{
+	"input": true, "select": true, "textarea": true, "button": true,
+	"label": true, "legend": true, "output": true, "datalist": true,
+}
+
+// Input names that carry anti-forgery tokens rather than user data
+var csrfFieldNames = []string{"csrf", "xsrf", "authenticity_token", "__requestverificationtoken", "_token"}
+
+// formField is one user-facing entry in a form summary
+type formField struct {
+	label       string
+	kind        string
+	required    bool
+	placeholder string
+	options     []string
+	name        string // radio group name, used to merge radios into one field
+}
+
+// renderForm summarises a <form> as a compact block an agent can act on:
+// a header line with the form's name, method and action, then one list item per
+// field with its label, type, required flag, options and placeholder. The rest
+// of the form renders as usual after it, without the controls the summary
+// covers: some sites wrap the whole page in one <form>.
+func (ctx *mdContext) renderForm(n *html.Node) {
+	if fields := ctx.collectFormFields(n); len(fields) > 0 {
+		form := &Form{
+			Name:   ctx.formName(n),
+			Method: strings.ToUpper(strings.TrimSpace(getAttr(n, "method"))),
+			Action: strings.TrimSpace(getAttr(n, "action")),
+		}
+		if form.Method == "" {
+			form.Method = "GET"
+		}
+		for _, f := range fields {
+			form.Fields = append(form.Fields, FormField{
+				Label:       f.label,
+				Kind:        f.kind,
+				Required:    f.required,
+				Placeholder: f.placeholder,
+				Options:     f.options,
+			})
+		}
+		ctx.add(form)
+	}
+
+	saved := ctx.inForm
+	ctx.inForm = true
+	ctx.children(n)
+	ctx.inForm = saved
+}
+
+// formName returns an author-supplied name for the form, if any
+func (ctx *mdContext) formName(n *html.Node) string {
+	if name := ctx.idrefText(getAttr(n, "aria-labelledby")); name != "" {
+		return name
+	}
+	if name := collapseSpace(getAttr(n, "aria-label")); name != "" {
+		return name
+	}
+	return collapseSpace(getAttr(n, "title"))
+}
+
+// collectFormFields walks a form in document order and returns its visible fields,
+// leaving out what the walk would and anything hidden from sighted users.
+// Radio buttons sharing a name are merged into a single field listing each choice.
+func (ctx *mdContext) collectFormFields(form *html.Node) []formField {
+	var fields []formField
+	radioGroups := make(map[string]int) // radio name -> index into fields
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if ctx.excluded(c) {
+				continue
+			}
+			// Users never see, and so never fill in, hidden controls: honeypots
+			if !hasAttr(c, "data-llm", "keep") && !ctx.isPanel(c) && visuallyHidden(c) != "" {
+				continue
+			}
+
+			switch c.Data {
+			case "input":
+				field, ok := ctx.inputField(c)
+				if !ok {
+					continue
+				}
+				if field.kind == "radio" && field.name != "" {
+					if idx, seen := radioGroups[field.name]; seen {
+						fields[idx].options = append(fields[idx].options, field.options...)
+						fields[idx].required = fields[idx].required || field.required
+						continue
+					}
+					radioGroups[field.name] = len(fields)
+				}
+				fields = append(fields, field)
+			case "select":
+				if field := ctx.selectField(c); field.label != "" || len(field.options) > 0 {
+					fields = append(fields, field)
+				}
+			case "textarea":
+				field := formField{
+					label:       ctx.accessibleName(c),
+					kind:        "textarea",
+					required:    isRequired(c),
+					placeholder: collapseSpace(getAttr(c, "placeholder")),
+				}
+				if field.label == "" {
+					field.label = getAttr(c, "name")
+				}
+				if field.label != "" {
+					fields = append(fields, field)
+				}
+			case "button":
+				kind := strings.ToLower(getAttr(c, "type"))
+				if kind == "" {
+					kind = "submit"
+				}
+				label := ctx.accessibleName(c)
+				if label == "" {
+					continue
+				}
+				fields = append(fields, formField{label: label, kind: kind})
+			default:
+				f(c)
+			}
+		}
+	}
+	f(form)
+
+	return fields
+}
+
+// inputField describes an <input>, reporting false for inputs a user never sees
+func (ctx *mdContext) inputField(n *html.Node) (formField, bool) {
+	kind := strings.ToLower(strings.TrimSpace(getAttr(n, "type")))
+	if kind == "" {
+		kind = "text"
+	}
+	if kind == "hidden" || isCSRFField(n) {
+		return formField{}, false
+	}
+
+	field := formField{
+		kind:        kind,
+		required:    isRequired(n),
+		placeholder: collapseSpace(getAttr(n, "placeholder")),
+	}
+
+	switch kind {
+	case "submit", "reset", "button", "image":
+		field.label = ctx.accessibleName(n)
+		if field.label == "" {
+			return formField{}, false
+		}
+		if kind == "image" {
+			field.kind = "submit"
+		}
+		return field, true
+	case "radio":
+		// The group is labelled by its fieldset legend; each radio is an option
+		field.name = getAttr(n, "name")
+		option := ctx.accessibleName(n)
+		if option == "" {
+			option = collapseSpace(getAttr(n, "value"))
+		}
+		if option != "" {
+			field.options = []string{option}
+		}
+		field.label = ctx.legendFor(n)
+		if field.label == "" {
+			field.label = field.name
+		}
+		return field, field.label != "" || len(field.options) > 0
+	}
+
+	field.label = ctx.accessibleName(n)
+	if field.label == "" {
+		field.label = getAttr(n, "name")
+	}
+	return field, field.label != ""
+}
+
+// selectField describes a <select> and its options, flattening optgroups
+func (ctx *mdContext) selectField(n *html.Node) formField {
+	kind := "select"
+	if hasAttrKey(n, "multiple") {
+		kind = "multi-select"
+	}
+	field := formField{
+		label:    ctx.accessibleName(n),
+		kind:     kind,
+		required: isRequired(n),
+	}
+	if field.label == "" {
+		field.label = getAttr(n, "name")
+	}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "option":
+				text := collapseSpace(getAttr(c, "label"))
+				if text == "" {
+					text = textContent(c)
+				}
+				if text == "" {
+					continue
+				}
+				// A leading "Choose one..." option with no value is a placeholder
+				if hasAttr(c, "value", "") && len(field.options) == 0 && field.placeholder == "" {
+					field.placeholder = text
+					continue
+				}
+				field.options = append(field.options, text)
+			case "optgroup":
+				f(c)
+			}
+		}
+	}
+	f(n)
+
+	return field
+}
+
+// labelFor finds the <label> whose for attribute names the given id,
+// indexing the document's labels on first use
+func (ctx *mdContext) labelFor(id string) *html.Node {
+	if ctx.labels == nil {
+		ctx.labels = make(map[string]*html.Node)
+		var f func(*html.Node)
+		f = func(n *html.Node) {
+			if n.Type == html.ElementNode && n.Data == "label" {
+				if id := getAttr(n, "for"); id != "" {
+					if _, dup := ctx.labels[id]; !dup {
+						ctx.labels[id] = n
+					}
+				}
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				f(c)
+			}
+		}
+		if ctx.doc != nil {
+			f(ctx.doc)
+		}
+	}
+	return ctx.labels[id]
+}
+
+// legendFor returns the legend of the nearest enclosing fieldset
+func (ctx *mdContext) legendFor(n *html.Node) string {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == "fieldset" {
+			if legend := findChild(p, "legend"); legend != nil {
+				return textContent(legend)
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// labelText returns a label's text without the text of controls nested inside it
+func labelText(label *html.Node) string {
+	var b strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+				b.WriteByte(' ')
+			case c.Type == html.ElementNode && (c.Data == "select" || c.Data == "textarea" || c.Data == "button"):
+				// Option and button text is not part of the label
+			default:
+				f(c)
+			}
+		}
+	}
+	f(label)
+	return strings.TrimSuffix(collapseSpace(b.String()), ":")
+}
+
+func isRequired(n *html.Node) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == "required" || (attr.Key == "aria-required" && attr.Val == "true") {
+			return true
+		}
+	}
+	return false
+}
+
+func isCSRFField(n *html.Node) bool {
+	name := strings.ToLower(getAttr(n, "name"))
+	if name == "" {
+		return false
+	}
+	for _, token := range csrfFieldNames {
+		if strings.Contains(name, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinOptions(options []string) string {
+	if len(options) <= maxFormOptions {
+		return strings.Join(options, ", ")
+	}
+	more := len(options) - maxFormOptions
+	return strings.Join(options[:maxFormOptions], ", ") + " (" + strconv.Itoa(more) + " more)"
+}
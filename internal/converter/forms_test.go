@@ -0,0 +1,133 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_FormSummary(t *testing.T) {
+	input := []byte(`<html><body>
+		<form action="/signup" method="post" aria-label="Sign up">
+			<input type="hidden" name="csrf_token" value="abc123">
+			<input type="hidden" name="ref" value="home">
+			<p>Create your account</p>
+			<label for="email">Email address</label>
+			<input id="email" type="email" name="email" required placeholder="you@example.com">
+			<label>Password <input type="password" name="pw" required></label>
+			<select name="plan" aria-label="Plan">
+				<option value="">Choose a plan</option>
+				<option>Free</option>
+				<optgroup label="Paid"><option>Pro</option><option>Team</option></optgroup>
+			</select>
+			<textarea name="bio" placeholder="Tell us about yourself"></textarea>
+			<button>Create account</button>
+		</form>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	expected := []string{
+		"**Form: Sign up** (POST /signup)",
+		`- Email address (email, required, placeholder "you@example.com")`,
+		"- Password (password, required)",
+		`- Plan (select, placeholder "Choose a plan"): Free, Pro, Team`,
+		"- Tell us about yourself (textarea)",
+		"- Create account (submit)",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected %q, got: %s", e, result)
+		}
+	}
+
+	for _, leaked := range []string{"csrf", "abc123", "ref", "Email address\n"} {
+		if strings.Contains(result, leaked) {
+			t.Errorf("Form summary should not contain %q, got: %s", leaked, result)
+		}
+	}
+	if !strings.HasSuffix(result, "- Create account (submit)\n\nCreate your account") {
+		t.Errorf("Expected the form's text after its summary, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_FormWrapsPage(t *testing.T) {
+	// ASP.NET Web Forms wrap the whole page in one <form>
+	input := []byte(`<html><body><form method="post" action="/default.aspx">
+		<input type="hidden" name="__VIEWSTATE" value="dDwtMTA4">
+		<header><input type="search" name="q" aria-label="Search"></header>
+		<main>
+			<h1>Quarterly results</h1>
+			<p>Revenue grew <strong>12%</strong>.</p>
+			<label for="agree">I agree</label><input type="checkbox" id="agree">
+		</main>
+	</form></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	expected := "**Form** (POST /default.aspx)\n- I agree (checkbox)\n\n# Quarterly results\n\nRevenue grew **12%**."
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHTMLToMarkdown_FormRadioGroup(t *testing.T) {
+	input := []byte(`<html><body>
+		<form>
+			<fieldset>
+				<legend>Shipping</legend>
+				<label><input type="radio" name="ship" value="std"> Standard</label>
+				<label><input type="radio" name="ship" value="exp" required> Express</label>
+			</fieldset>
+			<input type="checkbox" id="terms"><label for="terms">I agree</label>
+		</form>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	for _, e := range []string{
+		"**Form** (GET)",
+		"- Shipping (radio, required): Standard, Express",
+		"- I agree (checkbox)",
+	} {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected %q, got: %s", e, result)
+		}
+	}
+}
+
+func TestHTMLToMarkdown_FormLongOptionList(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString(`<html><body><form><select aria-label="Country">`)
+	for i := 0; i < maxFormOptions+5; i++ {
+		sb.WriteString("<option>C" + itoa(i) + "</option>")
+	}
+	sb.WriteString(`</select></form></body></html>`)
+
+	result, _ := HTMLToMarkdown([]byte(sb.String()), StripConfig{})
+	if !strings.Contains(result, "(5 more)") {
+		t.Errorf("Expected truncated option list, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_FormWithoutFields(t *testing.T) {
+	input := []byte(`<html><body><p>Before</p><form><input type="hidden" name="x"></form><p>After</p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if expected := "Before\n\nAfter"; result != expected {
+		t.Errorf("Form with no visible fields should render no summary, expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHTMLToMarkdown_FormHoneypot(t *testing.T) {
+	input := []byte(`<html><body><form>
+		<input name="hp" style="display:none">
+		<input name="hp2" hidden>
+		<input name="hp3" style="opacity:0">
+		<div style="position:absolute;left:-9999px"><input name="hp4"></div>
+		<input name="email" aria-label="Email">
+	</form></body></html>`)
+
+	for _, cfg := range []StripConfig{{}, {SkipHidden: true}, {Defense: DefenseFlag}} {
+		result, _ := HTMLToMarkdown(input, cfg)
+		if expected := "**Form** (GET)\n- Email (text)"; result != expected {
+			t.Errorf("%+v: expected %q, got: %q", cfg, expected, result)
+		}
+	}
+}
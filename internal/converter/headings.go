@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var headingTags = map[string]bool{"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true}
+
+// HeadingConfig normalizes the heading hierarchy. The zero value copies
+// source levels one-to-one.
+type HeadingConfig struct {
+	MaxDepth      int  // Deeper headings are clamped to this level; 0 means no limit
+	FixGaps       bool // Never skip a level: h1 followed by h4 becomes # then ##
+	DemoteExtraH1 bool // Only the first h1 stays level 1; later h1s become level 2
+	Offset        int  // Added to every level, to embed the output under another heading
+}
+
+// headingState tracks what the normalizer has seen so far
+type headingState struct {
+	seenH1 bool
+	stack  []openHeading // Open sections for gap repair
+}
+
+type openHeading struct {
+	source, out int
+}
+
+// headingLevel maps a source heading level to its markdown level
+func (ctx *mdContext) headingLevel(level int) int {
+	cfg := ctx.headings
+	if cfg.DemoteExtraH1 && level == 1 {
+		if ctx.headingState.seenH1 {
+			level = 2
+		}
+		ctx.headingState.seenH1 = true
+	}
+
+	if cfg.FixGaps {
+		stack := ctx.headingState.stack
+		for len(stack) > 0 && stack[len(stack)-1].source >= level {
+			stack = stack[:len(stack)-1]
+		}
+		out := 1
+		if len(stack) > 0 {
+			out = stack[len(stack)-1].out + 1
+		}
+		ctx.headingState.stack = append(stack, openHeading{source: level, out: out})
+		level = out
+	}
+
+	level += cfg.Offset
+	if cfg.MaxDepth > 0 && level > cfg.MaxDepth {
+		level = cfg.MaxDepth
+	}
+	return max(level, 1)
+}
+
+// maxHeadingLevel is the deepest level a heading can render at
+func (ctx *mdContext) maxHeadingLevel() int {
+	if ctx.headings.MaxDepth > 0 {
+		return min(ctx.headings.MaxDepth, 6)
+	}
+	return 6
+}
+
+// renderHeading builds h1-h6 at their normalized level. Headings without
+// text are dropped.
+func (ctx *mdContext) renderHeading(n *html.Node) {
+	heading := &Heading{}
+	heading.Children = ctx.build(func() { ctx.children(n) })
+	if strings.TrimSpace(ctx.md.render(heading.Children)) == "" {
+		return
+	}
+
+	heading.Level = ctx.headingLevel(int(n.Data[1] - '0'))
+	ctx.lastHeading = heading.Level
+	heading.ID = ctx.headingAnchor(n)
+	ctx.add(heading)
+}
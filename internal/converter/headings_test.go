@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_HeadingNormalization(t *testing.T) {
+	headingLines := func(md string) string {
+		var lines []string
+		for _, line := range strings.Split(md, "\n") {
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "**") {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "|")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		cfg      HeadingConfig
+		expected string
+	}{
+		{
+			"unchanged by default",
+			`<h1>A</h1><h4>B</h4><h6>C</h6>`,
+			HeadingConfig{},
+			"# A|#### B|###### C",
+		},
+		{
+			"max depth clamps deep headings",
+			`<h1>Title</h1><h2>Section</h2><h3>Sub</h3><h4>Deeper</h4><h6>Deepest</h6>`,
+			HeadingConfig{MaxDepth: 3},
+			"# Title|## Section|### Sub|### Deeper|### Deepest",
+		},
+		{
+			"gap repair",
+			`<h1>A</h1><h4>B</h4><h5>C</h5><h2>D</h2><h4>E</h4>`,
+			HeadingConfig{FixGaps: true},
+			"# A|## B|### C|## D|### E",
+		},
+		{
+			"gap repair from a deep start",
+			`<h3>A</h3><h5>B</h5>`,
+			HeadingConfig{FixGaps: true},
+			"# A|## B",
+		},
+		{
+			"demote extra h1",
+			`<h1>Site</h1><h1>Article</h1><h2>Part</h2>`,
+			HeadingConfig{DemoteExtraH1: true},
+			"# Site|## Article|## Part",
+		},
+		{
+			"offset",
+			`<h1>A</h1><h2>B</h2><h6>C</h6>`,
+			HeadingConfig{Offset: 2},
+			"### A|#### B|**C**",
+		},
+		{
+			"offset within max depth",
+			`<h1>A</h1><h3>B</h3>`,
+			HeadingConfig{Offset: 1, MaxDepth: 3, FixGaps: true},
+			"## A|### B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body>" + tt.input + "</body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{Headings: tt.cfg})
+			if got := headingLines(result); got != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_EmptyHeading(t *testing.T) {
+	input := []byte(`<html><body><h2><span></span></h2><p>Text</p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if result != "Text" {
+		t.Errorf("Empty headings should not leave markers, got: %q", result)
+	}
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// isHidden reports whether a browser would not display n. Only attributes and
+// inline styles are considered; stylesheets are not evaluated.
+//
+// Hidden are: <template>, a <dialog> without open, the hidden attribute (except
+// hidden="until-found", which find-in-page reveals), and inline styles with
+// display:none, visibility:hidden/collapse or content-visibility:hidden.
+// A closed <details> is not hidden: its body is one click away and usually
+// holds real content (FAQ answers, specs).
+func isHidden(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+
+	switch n.Data {
+	case "template":
+		return true
+	case "dialog":
+		if !hasAttrKey(n, "open") {
+			return true
+		}
+	}
+
+	for _, attr := range n.Attr {
+		if attr.Key == "hidden" && !strings.EqualFold(attr.Val, "until-found") {
+			return true
+		}
+	}
+
+	style := inlineStyle(n)
+	switch {
+	case style["display"] == "none":
+		return true
+	case style["visibility"] == "hidden", style["visibility"] == "collapse":
+		return true
+	case style["content-visibility"] == "hidden":
+		return true
+	}
+	return false
+}
+
+// inlineStyle parses the style attribute into lowercase property/value pairs,
+// dropping !important.
+func inlineStyle(n *html.Node) map[string]string {
+	raw := getAttr(n, "style")
+	if raw == "" {
+		return nil
+	}
+	decls := make(map[string]string)
+	for _, decl := range strings.Split(raw, ";") {
+		prop, val, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		val = strings.ToLower(strings.TrimSpace(val))
+		val = strings.TrimSpace(strings.TrimSuffix(val, "!important"))
+		decls[strings.ToLower(strings.TrimSpace(prop))] = val
+	}
+	return decls
+}
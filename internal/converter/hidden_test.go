@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestHTMLToMarkdown_SkipHidden(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>Visible text</p>
+		<template><p>Template markup</p></template>
+		<dialog><p>Closed modal</p></dialog>
+		<dialog open><p>Open modal</p></dialog>
+		<div hidden>Variant B</div>
+		<div hidden="until-found">Findable section</div>
+		<p style="display: none !important">Display none</p>
+		<p style="color:red; visibility:hidden">Invisible</p>
+		<div data-llm="keep" style="display:none">Kept anyway</div>
+		<details><summary>Question</summary><p>Collapsed answer</p></details>
+	</body></html>`)
+
+	result, _ := HTMLToMarkdown(input, StripConfig{SkipHidden: true})
+
+	for _, hidden := range []string{"Template markup", "Closed modal", "Variant B", "Display none", "Invisible"} {
+		if strings.Contains(result, hidden) {
+			t.Errorf("Result should not contain %q, got: %s", hidden, result)
+		}
+	}
+	for _, visible := range []string{"Visible text", "Open modal", "Findable section", "Kept anyway", "Question", "Collapsed answer"} {
+		if !strings.Contains(result, visible) {
+			t.Errorf("Result missing %q, got: %s", visible, result)
+		}
+	}
+}
+
+func TestHTMLToMarkdown_SkipHiddenDisabled(t *testing.T) {
+	input := []byte(`<html><body><div hidden>Variant B</div><p style="display:none">Display none</p></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if !strings.Contains(result, "Variant B") || !strings.Contains(result, "Display none") {
+		t.Errorf("Hidden content should render when SkipHidden is off, got: %s", result)
+	}
+}
+
+func TestInlineStyle(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<div style="DISPLAY : None ; Color: Red;;broken">x</div>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	div := findDescendant(doc, "div")
+
+	style := inlineStyle(div)
+	if style["display"] != "none" || style["color"] != "red" {
+		t.Errorf("Unexpected parsed style: %v", style)
+	}
+	if _, ok := style["broken"]; ok {
+		t.Errorf("Declarations without a value should be ignored: %v", style)
+	}
+}
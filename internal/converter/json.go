@@ -0,0 +1,377 @@
+package converter
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// JSONVersion is the version of the JSON output schema. Fields may be added
+// within a version; it changes when a field is removed or changes meaning.
+const JSONVersion = 1
+
+// JSONDocument is the JSON output of a converted page
+type JSONDocument struct {
+	Version  int          `json:"version"`
+	Metadata JSONMetadata `json:"metadata"`
+	Blocks   []JSONBlock  `json:"blocks"`
+}
+
+// Metadata describes the page a document was converted from
+type Metadata struct {
+	Title       string `json:"title,omitempty"`       // <title>
+	Lang        string `json:"lang,omitempty"`        // <html lang>
+	Description string `json:"description,omitempty"` // <meta name="description">
+}
+
+type JSONMetadata struct {
+	Metadata
+	Tokens   int      `json:"tokens"`             // Estimate for the markdown output
+	Warnings []string `json:"warnings,omitempty"` // Kind and reason of each Warning
+}
+
+// JSONBlock is one block of the JSON output. Type says which fields are set:
+//
+//	heading    level, anchor, text
+//	paragraph  text
+//	list       ordered, items: "item" blocks with text and nested lists in blocks
+//	table      caption, header, rows, more (rows left out by TableConfig.MaxRows)
+//	code       language, text
+//	image      alt, src
+//	math       text (TeX)
+//	quote      blocks
+//	figure     caption, blocks
+//	form       name, method, action, fields
+//	qa         question, text (the answer)
+//	footnote   label, text
+//	comments   blocks: one "thread" block per comment thread
+//	break      (a thematic break)
+//
+// Text is inline markdown in the configured dialect, so links and emphasis
+// survive; table cells and captions are single lines.
+type JSONBlock struct {
+	Type     string      `json:"type"`
+	Level    int         `json:"level,omitempty"`
+	Anchor   string      `json:"anchor,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Ordered  bool        `json:"ordered,omitempty"`
+	Items    []JSONBlock `json:"items,omitempty"`
+	Caption  string      `json:"caption,omitempty"`
+	Header   []string    `json:"header,omitempty"`
+	Rows     [][]string  `json:"rows,omitempty"`
+	More     int         `json:"more,omitempty"`
+	Language string      `json:"language,omitempty"`
+	Alt      string      `json:"alt,omitempty"`
+	Src      string      `json:"src,omitempty"`
+	Name     string      `json:"name,omitempty"`
+	Method   string      `json:"method,omitempty"`
+	Action   string      `json:"action,omitempty"`
+	Fields   []JSONField `json:"fields,omitempty"`
+	Question string      `json:"question,omitempty"`
+	Label    string      `json:"label,omitempty"`
+	Blocks   []JSONBlock `json:"blocks,omitempty"`
+}
+
+// JSONField is a form field of a "form" block
+type JSONField struct {
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// HTMLToJSON converts HTML to an encoded JSONDocument
+func HTMLToJSON(htmlContent []byte, stripConfig StripConfig) ([]byte, error) {
+	result, err := Convert(htmlContent, stripConfig)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(NewJSONDocument(result, stripConfig))
+}
+
+// NewJSONDocument builds the JSON output of a conversion made with stripConfig
+func NewJSONDocument(result Result, stripConfig StripConfig) JSONDocument {
+	r := &jsonRenderer{
+		md:      newMarkdownRenderer(stripConfig),
+		defense: stripConfig.Defense,
+	}
+	meta := JSONMetadata{
+		Metadata: Metadata{
+			Title:       r.text(result.Metadata.Title),
+			Lang:        result.Metadata.Lang,
+			Description: r.text(result.Metadata.Description),
+		},
+		Tokens: estimateTokens(result.Markdown),
+	}
+	for _, w := range result.Warnings {
+		meta.Warnings = append(meta.Warnings, w.String())
+	}
+
+	blocks := []JSONBlock{}
+	if result.Document != nil {
+		blocks = append(blocks, r.blocks(result.Document.Children)...)
+	}
+	return JSONDocument{Version: JSONVersion, Metadata: meta, Blocks: blocks}
+}
+
+// collectMetadata reads the page title, language and description
+func collectMetadata(doc *html.Node) Metadata {
+	var meta Metadata
+	root := findChild(doc, "html")
+	if root == nil {
+		return meta
+	}
+	meta.Lang = strings.TrimSpace(getAttr(root, "lang"))
+	head := findChild(root, "head")
+	if head == nil {
+		return meta
+	}
+	if title := findChild(head, "title"); title != nil {
+		meta.Title = textContent(title)
+	}
+	for c := head.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "meta" && strings.EqualFold(getAttr(c, "name"), "description") {
+			meta.Description = collapseSpace(getAttr(c, "content"))
+			break
+		}
+	}
+	return meta
+}
+
+// jsonRenderer turns the document model into JSON blocks. Inline content is
+// rendered by the markdown renderer of the configured dialect.
+type jsonRenderer struct {
+	md      *markdownRenderer
+	defense DefenseMode
+}
+
+// Blank lines between paragraphs of a run of loose inline content
+var paragraphBreak = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// blocks returns the blocks of a node list. Inline nodes outside a paragraph
+// (text of a <div>, say) are gathered into runs that become paragraphs.
+func (r *jsonRenderer) blocks(nodes []Node) []JSONBlock {
+	var run []Node
+	var blocks []JSONBlock
+	flush := func() {
+		blocks = append(blocks, r.inline(run)...)
+		run = nil
+	}
+	for _, n := range nodes {
+		if isInline(n) {
+			run = append(run, n)
+			continue
+		}
+		flush()
+		blocks = append(blocks, r.block(n)...)
+	}
+	flush()
+	return blocks
+}
+
+// isInline reports whether a node belongs in running text
+func isInline(n Node) bool {
+	switch n := n.(type) {
+	case *Text, *Format, *Code, *Link, *Image, *LineBreak, *FootnoteRef:
+		return true
+	case *Math:
+		return !n.Display
+	}
+	return false
+}
+
+// inline returns the paragraphs of a run of inline nodes, or an image block
+// when the run is just an image
+func (r *jsonRenderer) inline(run []Node) []JSONBlock {
+	if img := loneImage(run); img != nil {
+		return []JSONBlock{{Type: "image", Alt: r.text(img.Alt), Src: img.Src}}
+	}
+	var blocks []JSONBlock
+	for _, p := range paragraphBreak.Split(r.md.render(run), -1) {
+		if text := r.text(p); text != "" {
+			blocks = append(blocks, JSONBlock{Type: "paragraph", Text: text})
+		}
+	}
+	return blocks
+}
+
+// loneImage returns the image of a run that holds nothing else but whitespace
+func loneImage(run []Node) *Image {
+	var img *Image
+	for _, n := range run {
+		switch n := n.(type) {
+		case *Image:
+			if img != nil {
+				return nil
+			}
+			img = n
+		case *Text:
+			if strings.TrimSpace(n.Text) != "" {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return img
+}
+
+func (r *jsonRenderer) block(n Node) []JSONBlock {
+	switch n := n.(type) {
+	case *Document:
+		return r.blocks(n.Children)
+	case *Section:
+		return r.blocks(n.Children)
+	case *Paragraph:
+		return r.inline(n.Children)
+	case *Heading:
+		text := r.text(r.md.render(n.Children))
+		if text == "" {
+			return nil
+		}
+		return []JSONBlock{{Type: "heading", Level: n.Level, Anchor: n.ID, Text: text}}
+	case *Title:
+		if n.Level == 0 {
+			return []JSONBlock{{Type: "paragraph", Text: r.text(r.md.strong(n.Text))}}
+		}
+		return []JSONBlock{{Type: "heading", Level: n.Level, Text: r.text(n.Text)}}
+	case *List:
+		return []JSONBlock{r.list(n)}
+	case *ListItem:
+		return r.blocks(n.Children)
+	case *Table:
+		return r.table(n)
+	case *CodeBlock:
+		code := strings.Trim(r.md.render(n.Children), "\n")
+		return []JSONBlock{{Type: "code", Language: n.Lang, Text: r.screen(code)}}
+	case *Math:
+		if n.TeX == "" {
+			return nil
+		}
+		return []JSONBlock{{Type: "math", Text: r.screen(n.TeX)}}
+	case *ThematicBreak:
+		return []JSONBlock{{Type: "break"}}
+	case *Quote:
+		return r.nested(JSONBlock{Type: "quote"}, n.Children)
+	case *Figure:
+		return r.nested(JSONBlock{Type: "figure", Caption: r.text(n.Caption)}, n.Children)
+	case *Form:
+		return []JSONBlock{r.form(n)}
+	case *QA:
+		return []JSONBlock{{Type: "qa", Question: r.text(n.Question), Text: r.text(r.md.answer(n))}}
+	case *Footnotes:
+		var blocks []JSONBlock
+		for _, c := range n.Children {
+			if note, ok := c.(*Footnote); ok {
+				if text := r.text(collapseSpace(r.md.render(note.Children))); text != "" {
+					blocks = append(blocks, JSONBlock{Type: "footnote", Label: note.Label, Text: text})
+				}
+			}
+		}
+		return blocks
+	case *Comments:
+		comments := JSONBlock{Type: "comments"}
+		for _, thread := range n.Children {
+			if blocks := r.block(thread); len(blocks) > 0 {
+				comments.Blocks = append(comments.Blocks, JSONBlock{Type: "thread", Blocks: blocks})
+			}
+		}
+		if len(comments.Blocks) == 0 {
+			return nil
+		}
+		return []JSONBlock{comments}
+	}
+	return nil
+}
+
+// nested returns a container block holding the blocks of children, or nothing
+// when they are empty
+func (r *jsonRenderer) nested(b JSONBlock, children []Node) []JSONBlock {
+	b.Blocks = r.blocks(children)
+	if len(b.Blocks) == 0 && b.Caption == "" {
+		return nil
+	}
+	return []JSONBlock{b}
+}
+
+// list returns a list block. An item's text is its own content; lists nested
+// in it become its blocks.
+func (r *jsonRenderer) list(l *List) JSONBlock {
+	list := JSONBlock{Type: "list", Ordered: l.Ordered}
+	for _, c := range l.Children {
+		li, ok := c.(*ListItem)
+		if !ok {
+			continue
+		}
+		item := JSONBlock{Type: "item"}
+		var content []Node
+		for _, n := range li.Children {
+			if sub, ok := n.(*List); ok {
+				item.Blocks = append(item.Blocks, r.list(sub))
+			} else {
+				content = append(content, n)
+			}
+		}
+		item.Text = r.text(multipleNewlines.ReplaceAllString(r.md.render(content), "\n\n"))
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+// table returns a table block. A leading header row becomes the header.
+func (r *jsonRenderer) table(t *Table) []JSONBlock {
+	rows, more := r.md.limitRows(tableRows(t))
+	if len(rows) == 0 {
+		return nil
+	}
+	table := JSONBlock{Type: "table", Caption: r.text(r.md.tableCaption(t)), More: more}
+	cells := r.md.tableCells(rows)
+	for i := range cells {
+		for j := range cells[i] {
+			cells[i][j] = r.screen(cells[i][j])
+		}
+	}
+	if rows[0].Header {
+		table.Header, cells = cells[0], cells[1:]
+	}
+	table.Rows = cells
+	return []JSONBlock{table}
+}
+
+func (r *jsonRenderer) form(f *Form) JSONBlock {
+	form := JSONBlock{Type: "form", Name: r.text(f.Name), Method: f.Method, Action: f.Action}
+	for _, field := range f.Fields {
+		var options []string
+		for _, option := range field.Options {
+			if option = r.text(option); option != "" {
+				options = append(options, option)
+			}
+		}
+		form.Fields = append(form.Fields, JSONField{
+			Label:       r.text(field.Label),
+			Type:        field.Kind,
+			Required:    field.Required,
+			Placeholder: r.text(field.Placeholder),
+			Options:     options,
+		})
+	}
+	return form
+}
+
+// text returns trimmed, screened text
+func (r *jsonRenderer) text(s string) string {
+	return strings.TrimSpace(r.screen(strings.TrimSpace(s)))
+}
+
+// screen applies prompt injection defense to text. The warnings were reported
+// by the markdown conversion already.
+func (r *jsonRenderer) screen(s string) string {
+	if r.defense == DefenseOff || s == "" {
+		return s
+	}
+	s, _ = screenInstructions(s, r.defense)
+	return s
+}
@@ -0,0 +1,551 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// markdownRenderer writes the document model as markdown in one dialect
+type markdownRenderer struct {
+	dialect Dialect
+	anchors AnchorStyle
+	tables  TableConfig
+	rules   map[string]mdRule
+}
+
+func newMarkdownRenderer(cfg StripConfig) *markdownRenderer {
+	rules, ok := dialectRules[cfg.Dialect]
+	if !ok {
+		rules = wrapRules
+	}
+	return &markdownRenderer{dialect: cfg.Dialect, anchors: cfg.Anchors, tables: cfg.Tables, rules: rules}
+}
+
+// render returns the markdown of a node list
+func (r *markdownRenderer) render(nodes []Node) string {
+	var b strings.Builder
+	r.write(&b, nodes)
+	return b.String()
+}
+
+// write writes a node list. Inline elements whose markers sit between words
+// (formatting, links, code, inline math) are kept a space apart from their
+// neighbours: text is trimmed, so the space cannot come from the page.
+// Opening brackets and the punctuation that follows a word stay attached,
+// as in "(**bold**)." or "claim[^1].".
+func (r *markdownRenderer) write(b *strings.Builder, nodes []Node) {
+	var part strings.Builder
+	spaceAfter, wrote := false, false
+	for _, n := range nodes {
+		before, after := r.spacing(n)
+		if !wrote || !before && !spaceAfter {
+			start := b.Len()
+			r.writeNode(b, n)
+			if b.Len() > start {
+				spaceAfter, wrote = after, true
+			}
+			continue
+		}
+		part.Reset()
+		r.writeNode(&part, n)
+		s := part.String()
+		if s == "" {
+			continue
+		}
+		last, _ := utf8.DecodeLastRuneInString(b.String())
+		first, _ := utf8.DecodeRuneInString(s)
+		if !unicode.IsSpace(last) && !strings.ContainsRune("([{“‘", last) &&
+			!unicode.IsSpace(first) && !strings.ContainsRune(".,;:!?)]}”’%…", first) {
+			b.WriteString(" ")
+		}
+		b.WriteString(s)
+		spaceAfter = after
+	}
+}
+
+// spacing reports whether n is set apart from the node before and after it
+func (r *markdownRenderer) spacing(n Node) (before, after bool) {
+	switch n := n.(type) {
+	case *Format:
+		rule := r.rules[n.Tag]
+		return strings.HasPrefix(rule.prefix, " "), strings.HasSuffix(rule.suffix, " ")
+	case *Link, *Code:
+		return true, true
+	case *Math:
+		return !n.Display, !n.Display
+	case *FootnoteRef:
+		return false, true
+	}
+	return false, false
+}
+
+func (r *markdownRenderer) writeNode(b *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case *Text:
+		b.WriteString(n.Text)
+	case *Document:
+		r.write(b, n.Children)
+	case *Section:
+		r.write(b, n.Children)
+	case *Format:
+		r.wrapInline(b, n.Tag, n.Children)
+	case *Paragraph:
+		r.wrap(b, "p", n.Children)
+	case *Quote:
+		r.wrap(b, "blockquote", n.Children)
+	case *TableCaption:
+		r.wrap(b, "caption", n.Children)
+	case *TableRow:
+		r.wrap(b, "tr", n.Children)
+	case *TableCell:
+		if n.Header {
+			r.wrap(b, "th", n.Children)
+		} else {
+			r.wrap(b, "td", n.Children)
+		}
+	case *Heading:
+		if line := r.headingLine(n); line != "" {
+			b.WriteString("\n" + line + "\n\n")
+		}
+	case *Title:
+		if n.Level == 0 {
+			b.WriteString("\n" + r.strong(n.Text) + "\n\n")
+		} else {
+			b.WriteString("\n" + r.headingPrefix(n.Level) + n.Text + "\n\n")
+		}
+	case *List:
+		b.WriteString("\n")
+		r.write(b, n.Children)
+		b.WriteString("\n")
+	case *ListItem:
+		b.WriteString(strings.Repeat("  ", max(n.Depth-1, 0)))
+		if n.Number > 0 {
+			b.WriteString(itoa(n.Number) + ". ")
+		} else {
+			b.WriteString("- ")
+		}
+		r.write(b, n.Children)
+		b.WriteString("\n")
+	case *Table:
+		r.writeTable(b, n)
+	case *CodeBlock:
+		fence := "```"
+		if r.dialect == DialectPlain {
+			fence = ""
+		}
+		b.WriteString("\n" + fence + "\n")
+		r.write(b, n.Children)
+		b.WriteString("\n" + fence + "\n\n")
+	case *ThematicBreak:
+		switch r.dialect {
+		case DialectPlain:
+			b.WriteString("\n\n")
+		case DialectExtended:
+			b.WriteString("\n---\n\n")
+		default:
+			// A blank line keeps --- from turning the line above into a heading
+			b.WriteString("\n\n---\n\n")
+		}
+	case *LineBreak:
+		b.WriteString("\n")
+	case *Code:
+		if r.dialect == DialectPlain {
+			r.write(b, n.Children)
+			return
+		}
+		b.WriteString("`")
+		r.write(b, n.Children)
+		b.WriteString("`")
+	case *Link:
+		r.writeLink(b, n)
+	case *Image:
+		if r.dialect == DialectPlain {
+			b.WriteString(n.Alt)
+			return
+		}
+		if n.Alt != "" {
+			b.WriteString("[Image: " + n.Alt + "]")
+		} else {
+			b.WriteString("[Image]")
+		}
+	case *Math:
+		r.writeMath(b, n)
+	case *FootnoteRef:
+		b.WriteString(r.footnoteRef(n.Label))
+	case *Footnotes:
+		b.WriteString("\n\n")
+		for _, c := range n.Children {
+			if note, ok := c.(*Footnote); ok {
+				r.writeFootnote(b, note)
+			}
+		}
+	case *Figure:
+		b.WriteString("\n\n")
+		r.write(b, n.Children)
+		if n.Caption != "" {
+			b.WriteString("\n" + r.emphasis("Figure: "+n.Caption))
+		}
+		b.WriteString("\n\n")
+	case *Form:
+		r.writeForm(b, n)
+	case *QA:
+		b.WriteString("\nQ: " + n.Question + "\nA: " + r.answer(n) + "\n\n")
+	case *Comments:
+		b.WriteString("\n\n" + r.headingPrefix(2) + "Comments\n\n")
+		for _, thread := range n.Children {
+			r.writeNode(b, thread)
+			b.WriteString("\n\n")
+		}
+	}
+}
+
+// wrap writes children with the dialect's wrap rule for tag. Empty wrappers
+// (icon <i>, spacer <b>) would leave stray markers behind, so they write
+// only their whitespace; table cells are kept so columns stay aligned.
+func (r *markdownRenderer) wrap(b *strings.Builder, tag string, children []Node) {
+	rule := r.rules[tag]
+	r.wrapWith(b, tag, rule.prefix, rule.suffix, children)
+}
+
+// wrapInline writes formatting with its wrap rule minus the padding around
+// it; write puts the spaces between words back (see spacing)
+func (r *markdownRenderer) wrapInline(b *strings.Builder, tag string, children []Node) {
+	rule := r.rules[tag]
+	r.wrapWith(b, tag, strings.TrimLeft(rule.prefix, " "), strings.TrimRight(rule.suffix, " "), children)
+}
+
+func (r *markdownRenderer) wrapWith(b *strings.Builder, tag, prefix, suffix string, children []Node) {
+	content := r.render(children)
+	if strings.TrimSpace(content) == "" && !tableCellTags[tag] {
+		b.WriteString(content)
+		return
+	}
+	b.WriteString(prefix + content + suffix)
+}
+
+// strong and emphasis wrap generated labels (form titles, captions, panel
+// names) the way <strong> and <em> are rendered
+func (r *markdownRenderer) strong(s string) string {
+	if r.dialect == DialectPlain {
+		return s
+	}
+	return "**" + s + "**"
+}
+
+func (r *markdownRenderer) emphasis(s string) string {
+	if r.dialect == DialectPlain {
+		return s
+	}
+	return "*" + s + "*"
+}
+
+// headingPrefix returns the marker of a heading line ("## "), empty in plain text
+func (r *markdownRenderer) headingPrefix(level int) string {
+	if r.dialect == DialectPlain {
+		return ""
+	}
+	return strings.Repeat("#", level) + " "
+}
+
+// headingLine returns the line a heading renders to, "" when it has no text
+func (r *markdownRenderer) headingLine(h *Heading) string {
+	content := strings.TrimSpace(r.render(h.Children))
+	if content == "" {
+		return ""
+	}
+	if h.Level > 6 {
+		return r.anchoredHeading("", r.strong(content), h.ID)
+	}
+	return r.anchoredHeading(r.headingPrefix(h.Level), content, h.ID)
+}
+
+// anchoredHeading returns a heading line with its anchor in the configured
+// style. An id an attribute cannot hold ("a b", "x}") is left out.
+func (r *markdownRenderer) anchoredHeading(prefix, content, id string) string {
+	style := r.anchorStyle()
+	switch {
+	case id == "" || style == AnchorsNone:
+		return prefix + content
+	case style == AnchorsAttribute && strings.ContainsFunc(id, func(c rune) bool {
+		return unicode.IsSpace(c) || c == '{' || c == '}'
+	}):
+		return prefix + content
+	case style == AnchorsHTML:
+		return prefix + `<a id="` + html.EscapeString(id) + `"></a>` + content
+	default:
+		return prefix + content + " {#" + id + "}"
+	}
+}
+
+// anchorStyle is the configured anchor style as far as the dialect supports
+// it: attribute anchors are an extension, so strict dialects use HTML anchors,
+// and plain text has none.
+func (r *markdownRenderer) anchorStyle() AnchorStyle {
+	switch {
+	case r.dialect == DialectPlain:
+		return AnchorsNone
+	case r.dialect != DialectExtended && r.anchors == AnchorsAttribute:
+		return AnchorsHTML
+	}
+	return r.anchors
+}
+
+func (r *markdownRenderer) writeLink(b *strings.Builder, n *Link) {
+	text := strings.TrimSpace(r.render(n.Children))
+	if r.dialect == DialectPlain {
+		// Plain text keeps what the reader sees, not where it leads
+		b.WriteString(text)
+		return
+	}
+	if n.Href == "" {
+		b.WriteString(text)
+		return
+	}
+	if text == "" {
+		text = n.Href
+	}
+	b.WriteString("[" + text + "](" + n.Href + ")")
+}
+
+// writeMath writes a formula. Inline formulas are kept apart from the words
+// around them (see spacing): most renderers do not parse $ glued to a word
+// as math.
+func (r *markdownRenderer) writeMath(b *strings.Builder, m *Math) {
+	if m.TeX == "" {
+		return
+	}
+	switch r.dialect {
+	case DialectCommonMark:
+		// No math syntax: a math code block or code span keeps the TeX verbatim
+		if m.Display {
+			b.WriteString("\n```math\n" + m.TeX + "\n```\n\n")
+		} else {
+			b.WriteString("`" + m.TeX + "`")
+		}
+	case DialectPlain:
+		if m.Display {
+			b.WriteString("\n" + m.TeX + "\n\n")
+		} else {
+			b.WriteString(m.TeX)
+		}
+	default:
+		if m.Display {
+			b.WriteString("\n$$" + m.TeX + "$$\n\n")
+		} else {
+			b.WriteString("$" + m.TeX + "$")
+		}
+	}
+}
+
+// footnoteRef returns the marker of a footnote reference. Dialects without
+// footnotes use a bracketed label.
+func (r *markdownRenderer) footnoteRef(label string) string {
+	if r.dialect == DialectExtended || r.dialect == DialectGFM {
+		return "[^" + label + "]"
+	}
+	return "[" + label + "]"
+}
+
+// writeFootnote writes one definition line, "[^label]: text"
+func (r *markdownRenderer) writeFootnote(b *strings.Builder, note *Footnote) {
+	text := strings.Join(strings.Fields(r.render(note.Children)), " ")
+	if text == "" {
+		return
+	}
+	if r.dialect == DialectExtended || r.dialect == DialectGFM {
+		b.WriteString("[^" + note.Label + "]: " + text + "\n")
+	} else {
+		// "[1]: text" would be a link reference definition, and vanish
+		b.WriteString("[" + note.Label + "] " + text + "\n")
+	}
+}
+
+// answer returns the markdown of a QA answer with its blank lines condensed
+func (r *markdownRenderer) answer(qa *QA) string {
+	return strings.TrimSpace(multipleNewlines.ReplaceAllString(r.render(qa.Children), "\n\n"))
+}
+
+// writeForm writes a form summary: a header line with the form's name, method
+// and action, then one list item per field with its label, type, required
+// flag, options and placeholder
+func (r *markdownRenderer) writeForm(b *strings.Builder, f *Form) {
+	title := "Form"
+	if f.Name != "" {
+		title += ": " + f.Name
+	}
+	b.WriteString("\n" + r.strong(title) + " (" + f.Method)
+	if f.Action != "" {
+		b.WriteString(" " + f.Action)
+	}
+	b.WriteString(")\n")
+
+	for _, field := range f.Fields {
+		b.WriteString("- " + field.Label + " (" + field.Kind)
+		if field.Required {
+			b.WriteString(", required")
+		}
+		if field.Placeholder != "" && field.Placeholder != field.Label {
+			b.WriteString(`, placeholder "` + field.Placeholder + `"`)
+		}
+		b.WriteString(")")
+		if len(field.Options) > 0 {
+			b.WriteString(": " + joinOptions(field.Options))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeMarkdownTable writes a table in the dialect's syntax. The extended
+// dialect renders rows through the wrap rules as they come; strict dialects
+// need the whole table first: a GFM pipe table, an HTML block for CommonMark,
+// or " | "-separated lines for plain text, quoting cells that contain "|". The first row is the header of a
+// pipe table.
+func (r *markdownRenderer) writeMarkdownTable(b *strings.Builder, t *Table) {
+	if r.dialect == DialectExtended {
+		rows := tableRows(t)
+		kept, more := r.limitRows(rows)
+		b.WriteString("\n")
+		if t.Title != "" {
+			b.WriteString("\n" + r.emphasis(t.Title) + "\n")
+		}
+		if more == 0 {
+			r.write(b, t.Children)
+			b.WriteString("\n")
+			return
+		}
+		dropped := make(map[Node]bool, more)
+		for _, row := range rows {
+			dropped[row] = true
+		}
+		for _, row := range kept {
+			delete(dropped, row)
+		}
+		for _, c := range t.Children {
+			if !dropped[c] {
+				r.writeNode(b, c)
+			}
+		}
+		b.WriteString("\n\n")
+		r.writeMoreRows(b, more)
+		return
+	}
+
+	rows, more := r.limitRows(tableRows(t))
+	if len(rows) == 0 {
+		return
+	}
+	caption := r.tableCaption(t)
+
+	b.WriteString("\n\n")
+	if caption != "" {
+		b.WriteString(r.emphasis(caption) + "\n\n")
+	}
+
+	cells := r.tableCells(rows)
+	width := 0
+	for _, row := range cells {
+		width = max(width, len(row))
+	}
+
+	switch r.dialect {
+	case DialectGFM:
+		for i := range rows {
+			b.WriteString("|")
+			for j := 0; j < width; j++ {
+				cell := ""
+				if j < len(cells[i]) {
+					cell = strings.ReplaceAll(cells[i][j], "|", `\|`)
+				}
+				b.WriteString(" " + cell + " |")
+			}
+			b.WriteString("\n")
+			if i == 0 {
+				b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+			}
+		}
+	case DialectCommonMark:
+		// An HTML block ends at a blank line, so cells are kept to one line
+		b.WriteString("<table>\n")
+		for i, row := range rows {
+			tag := "td"
+			if row.Header {
+				tag = "th"
+			}
+			b.WriteString("<tr>")
+			for _, cell := range cells[i] {
+				b.WriteString("<" + tag + ">" + html.EscapeString(cell) + "</" + tag + ">")
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n")
+	default:
+		for i := range rows {
+			row := make([]string, len(cells[i]))
+			for j, cell := range cells[i] {
+				row[j] = plainCell(cell)
+			}
+			b.WriteString(strings.Join(row, " | ") + "\n")
+		}
+	}
+	b.WriteString("\n")
+	r.writeMoreRows(b, more)
+}
+
+// plainCell quotes a plain-text cell that holds the separator, CSV style
+func plainCell(s string) string {
+	if !strings.Contains(s, "|") && !strings.HasPrefix(s, `"`) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// tableCaption returns the caption of a table, or the title of its figure
+func (r *markdownRenderer) tableCaption(t *Table) string {
+	for _, c := range t.Children {
+		if tc, ok := c.(*TableCaption); ok {
+			return collapseSpace(r.render(tc.Children))
+		}
+	}
+	return t.Title
+}
+
+// tableCells returns the rendered text of each row's cells, one line each
+func (r *markdownRenderer) tableCells(rows []*TableRow) [][]string {
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		for _, c := range row.Children {
+			if cell, ok := c.(*TableCell); ok {
+				cells[i] = append(cells[i], collapseSpace(r.render(cell.Children)))
+			}
+		}
+	}
+	return cells
+}
+
+// tableRows returns the rows of a table that have cells, leaving nested
+// tables' rows to their table
+func tableRows(t *Table) []*TableRow {
+	var rows []*TableRow
+	for _, c := range t.Children {
+		walkModel(c, func(n Node) bool {
+			switch n := n.(type) {
+			case *Table:
+				return false
+			case *TableRow:
+				for _, c := range n.Children {
+					if _, ok := c.(*TableCell); ok {
+						rows = append(rows, n)
+						break
+					}
+				}
+				return false
+			}
+			return true
+		})
+	}
+	return rows
+}
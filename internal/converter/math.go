@@ -0,0 +1,367 @@
+package converter
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Class names of MathJax v2 presentation output. The formula itself is read
+// from the accompanying <script type="math/tex">.
+var mathJaxOutputClasses = []string{
+	"MathJax", "MathJax_Preview", "MathJax_Display", "MathJax_SVG",
+	"MathJax_SVG_Display", "MathJax_CHTML", "MathJax_MathML",
+}
+
+// renderMathElement renders formulas as $…$ (inline) or $$…$$ (display) LaTeX.
+// It handles MathML, KaTeX and MathJax v2/v3 output and reports whether n was
+// consumed. Formulas hidden with aria-hidden are left to the strip check.
+func (ctx *mdContext) renderMathElement(n *html.Node) bool {
+	if hasAttr(n, "aria-hidden", "true") {
+		return false
+	}
+
+	switch {
+	case n.Data == "math":
+		ctx.writeMath(mathSource(n), mathIsDisplay(n))
+		return true
+
+	case n.Data == "mjx-container":
+		// MathJax v3 keeps an assistive MathML copy next to its rendering
+		if m := findDescendant(n, "math"); m != nil {
+			ctx.writeMath(mathSource(m), hasAttr(n, "display", "true") || mathIsDisplay(m))
+		}
+		return true
+
+	case hasClass(n, "katex"):
+		// KaTeX renders twice: .katex-mathml (with the TeX annotation) and
+		// an aria-hidden .katex-html layout
+		if m := findDescendant(n, "math"); m != nil {
+			display := mathIsDisplay(m) || (n.Parent != nil && hasClass(n.Parent, "katex-display"))
+			ctx.writeMath(mathSource(m), display)
+		}
+		return true
+
+	case n.Data == "script":
+		typ := strings.ToLower(getAttr(n, "type"))
+		display := strings.Contains(typ, "mode=display")
+		switch {
+		case strings.HasPrefix(typ, "math/tex"):
+			ctx.writeMath(strings.TrimSpace(scriptText(n)), display)
+			return true
+		case strings.HasPrefix(typ, "math/mml"):
+			if m := parseMathML(scriptText(n)); m != nil {
+				ctx.writeMath(mathSource(m), display || mathIsDisplay(m))
+			}
+			return true
+		}
+		return false
+	}
+
+	for _, class := range mathJaxOutputClasses {
+		if hasClass(n, class) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx *mdContext) writeMath(tex string, display bool) {
+	if tex != "" {
+		ctx.add(&Math{TeX: tex, Display: display})
+	}
+}
+
+func mathIsDisplay(m *html.Node) bool {
+	return hasAttr(m, "display", "block") || hasAttr(m, "mode", "display")
+}
+
+// mathSource returns the TeX for a <math> element: its TeX annotation when
+// present (KaTeX and many authoring tools emit one), otherwise a translation
+// of the presentation MathML.
+func mathSource(m *html.Node) string {
+	var tex string
+	var f func(*html.Node) bool
+	f = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.Data == "annotation" {
+				enc := strings.ToLower(getAttr(c, "encoding"))
+				if enc == "application/x-tex" || enc == "tex" {
+					tex = strings.TrimSpace(scriptText(c))
+					return true
+				}
+				continue
+			}
+			if f(c) {
+				return true
+			}
+		}
+		return false
+	}
+	if f(m) && tex != "" {
+		return tex
+	}
+	return strings.TrimSpace(mathToLatex(m))
+}
+
+// parseMathML parses a MathML string, as found in MathJax's math/mml scripts
+func parseMathML(s string) *html.Node {
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(s), context)
+	if err != nil {
+		return nil
+	}
+	holder := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, node := range nodes {
+		holder.AppendChild(node)
+	}
+	return findDescendant(holder, "math")
+}
+
+// TeX commands for symbols that commonly appear in <mi> and <mo>
+var texSymbols = map[rune]string{
+	'α': `\alpha`, 'β': `\beta`, 'γ': `\gamma`, 'δ': `\delta`, 'ε': `\varepsilon`,
+	'ϵ': `\epsilon`, 'ζ': `\zeta`, 'η': `\eta`, 'θ': `\theta`, 'ι': `\iota`,
+	'κ': `\kappa`, 'λ': `\lambda`, 'μ': `\mu`, 'ν': `\nu`, 'ξ': `\xi`, 'π': `\pi`,
+	'ρ': `\rho`, 'σ': `\sigma`, 'τ': `\tau`, 'υ': `\upsilon`, 'φ': `\varphi`,
+	'ϕ': `\phi`, 'χ': `\chi`, 'ψ': `\psi`, 'ω': `\omega`,
+	'Γ': `\Gamma`, 'Δ': `\Delta`, 'Θ': `\Theta`, 'Λ': `\Lambda`, 'Ξ': `\Xi`,
+	'Π': `\Pi`, 'Σ': `\Sigma`, 'Υ': `\Upsilon`, 'Φ': `\Phi`, 'Ψ': `\Psi`, 'Ω': `\Omega`,
+	'×': `\times`, '·': `\cdot`, '⋅': `\cdot`, '÷': `\div`, '±': `\pm`, '∓': `\mp`,
+	'−': `-`, '≤': `\leq`, '≥': `\geq`, '≠': `\neq`, '≈': `\approx`, '≡': `\equiv`,
+	'∼': `\sim`, '∝': `\propto`, '∞': `\infty`, '→': `\to`, '←': `\leftarrow`,
+	'↔': `\leftrightarrow`, '⇒': `\Rightarrow`, '⇔': `\Leftrightarrow`,
+	'∈': `\in`, '∉': `\notin`, '⊂': `\subset`, '⊆': `\subseteq`, '∪': `\cup`,
+	'∩': `\cap`, '∅': `\emptyset`, '∀': `\forall`, '∃': `\exists`, '¬': `\neg`,
+	'∧': `\land`, '∨': `\lor`, '∑': `\sum`, '∏': `\prod`, '∫': `\int`, '∮': `\oint`,
+	'∂': `\partial`, '∇': `\nabla`, '…': `\ldots`, '⋯': `\cdots`, '∘': `\circ`,
+	'⟨': `\langle`, '⟩': `\rangle`, '‖': `\|`, '′': `'`, '{': `\{`, '}': `\}`,
+	'\u2061': "", '\u2062': "", '\u2063': "", // Invisible function application, times, separator
+}
+
+// Multi-letter identifiers that TeX typesets as operator names
+var texFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"log": true, "ln": true, "exp": true, "lim": true, "max": true, "min": true,
+	"sup": true, "inf": true, "det": true, "gcd": true, "deg": true, "dim": true,
+	"ker": true, "arg": true, "Pr": true,
+}
+
+// Accent characters of <mover accent> and <munder> mapped to TeX commands
+var texOverAccents = map[string]string{
+	"^": `\hat`, "ˆ": `\hat`, "~": `\tilde`, "˜": `\tilde`, "¯": `\overline`,
+	"‾": `\overline`, "→": `\vec`, "\u20d7": `\vec`, "˙": `\dot`, "¨": `\ddot`, "⏞": `\overbrace`,
+}
+
+var texUnderAccents = map[string]string{
+	"_": `\underline`, "¯": `\underline`, "‾": `\underline`, "⏟": `\underbrace`,
+}
+
+// mathToLatex translates presentation MathML to TeX
+func mathToLatex(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return texSymbolsOf(strings.TrimSpace(n.Data))
+	}
+	if n.Type != html.ElementNode {
+		return ""
+	}
+
+	args := mathArgs(n)
+	arg := func(i int) string {
+		if i < len(args) {
+			return mathToLatex(args[i])
+		}
+		return ""
+	}
+
+	switch n.Data {
+	case "mi":
+		text := textContent(n)
+		switch {
+		case utf8.RuneCountInString(text) <= 1:
+			return texSymbolsOf(text)
+		case texFunctions[text]:
+			return `\` + text
+		}
+		return `\mathrm{` + text + `}`
+	case "mn", "mo":
+		return texSymbolsOf(textContent(n))
+	case "mtext", "ms":
+		if text := textContent(n); text != "" {
+			return `\text{` + text + `}`
+		}
+		return ""
+	case "mspace":
+		return " "
+	case "mfrac":
+		return `\frac{` + arg(0) + `}{` + arg(1) + `}`
+	case "msqrt":
+		return `\sqrt{` + mathJoin(args) + `}`
+	case "mroot":
+		return `\sqrt[` + arg(1) + `]{` + arg(0) + `}`
+	case "msup":
+		return texGroup(arg(0)) + "^" + texGroup(arg(1))
+	case "msub":
+		return texGroup(arg(0)) + "_" + texGroup(arg(1))
+	case "msubsup":
+		return texGroup(arg(0)) + "_" + texGroup(arg(1)) + "^" + texGroup(arg(2))
+	case "mover":
+		if len(args) > 1 {
+			if cmd, ok := texOverAccents[textContent(args[1])]; ok {
+				return cmd + "{" + arg(0) + "}"
+			}
+			if isLimitsBase(args[0]) {
+				return arg(0) + "^" + texGroup(arg(1))
+			}
+		}
+		return `\overset{` + arg(1) + `}{` + arg(0) + `}`
+	case "munder":
+		if len(args) > 1 {
+			if cmd, ok := texUnderAccents[textContent(args[1])]; ok {
+				return cmd + "{" + arg(0) + "}"
+			}
+			if isLimitsBase(args[0]) {
+				return arg(0) + "_" + texGroup(arg(1))
+			}
+		}
+		return `\underset{` + arg(1) + `}{` + arg(0) + `}`
+	case "munderover":
+		if len(args) > 0 && isLimitsBase(args[0]) {
+			return arg(0) + "_" + texGroup(arg(1)) + "^" + texGroup(arg(2))
+		}
+		return `\overset{` + arg(2) + `}{\underset{` + arg(1) + `}{` + arg(0) + `}}`
+	case "mfenced":
+		open, close := "(", ")"
+		if hasAttrKey(n, "open") {
+			open = getAttr(n, "open")
+		}
+		if hasAttrKey(n, "close") {
+			close = getAttr(n, "close")
+		}
+		sep := ","
+		if hasAttrKey(n, "separators") {
+			sep = strings.TrimSpace(getAttr(n, "separators"))
+			if sep != "" {
+				sep = string([]rune(sep)[:1])
+			}
+		}
+		parts := make([]string, len(args))
+		for i, a := range args {
+			parts[i] = mathToLatex(a)
+		}
+		return texSymbolsOf(open) + strings.Join(parts, sep) + texSymbolsOf(close)
+	case "mtable":
+		var rows []string
+		for _, row := range args {
+			cols := mathArgs(row)
+			if row.Data == "mlabeledtr" && len(cols) > 0 {
+				cols = cols[1:] // The equation label
+			}
+			var cells []string
+			for _, cell := range cols {
+				cells = append(cells, mathToLatex(cell))
+			}
+			rows = append(rows, strings.Join(cells, " & "))
+		}
+		return `\begin{matrix}` + strings.Join(rows, ` \\ `) + `\end{matrix}`
+	case "mphantom", "annotation", "annotation-xml", "none", "mprescripts":
+		return ""
+	case "semantics":
+		return arg(0)
+	}
+
+	// math, mrow, mstyle, mpadded, menclose, merror, mtd and unknown elements
+	return mathJoin(args)
+}
+
+// mathArgs returns the element children of a MathML node
+func mathArgs(n *html.Node) []*html.Node {
+	var args []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			args = append(args, c)
+		}
+	}
+	return args
+}
+
+// mathJoin concatenates translated nodes with the spacing needsTexSpace requires
+func mathJoin(nodes []*html.Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		part := mathToLatex(n)
+		if part == "" {
+			continue
+		}
+		if needsTexSpace(b.String(), part) {
+			b.WriteString(" ")
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// texSymbolsOf replaces symbols with their TeX commands
+func texSymbolsOf(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		cmd, ok := texSymbols[r]
+		if !ok {
+			cmd = string(r)
+		}
+		if needsTexSpace(b.String(), cmd) {
+			b.WriteString(" ")
+		}
+		b.WriteString(cmd)
+	}
+	return b.String()
+}
+
+// texGroup braces a script or operand unless it is a single character
+func texGroup(s string) string {
+	if utf8.RuneCountInString(s) == 1 {
+		return s
+	}
+	return "{" + s + "}"
+}
+
+// isLimitsBase reports whether under/over scripts on n are limits (∑, ∫, lim)
+// rather than accents or stacked notation
+func isLimitsBase(n *html.Node) bool {
+	return n.Data == "mo" || (n.Data == "mi" && texFunctions[textContent(n)])
+}
+
+// needsTexSpace reports whether next must be separated from prev: a control
+// word or single-letter script followed by a letter ("\alpha x", "x_i y", not
+// "\alphax" or "x_iy")
+func needsTexSpace(prev, next string) bool {
+	if !startsWithLetter(next) {
+		return false
+	}
+	if n := len(prev); n >= 2 && (prev[n-2] == '^' || prev[n-2] == '_') && isASCIILetter(prev[n-1]) {
+		return true
+	}
+	return endsWithControlWord(prev)
+}
+
+func endsWithControlWord(s string) bool {
+	i := len(s)
+	for i > 0 && isASCIILetter(s[i-1]) {
+		i--
+	}
+	return i < len(s) && i > 0 && s[i-1] == '\\'
+}
+
+func startsWithLetter(s string) bool {
+	return s != "" && isASCIILetter(s[0])
+}
+
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_MathML(t *testing.T) {
+	tests := []struct {
+		name     string
+		math     string
+		expected string
+	}{
+		{
+			"fraction",
+			`<math><mfrac><mn>1</mn><mn>2</mn></mfrac></math>`,
+			`$\frac{1}{2}$`,
+		},
+		{
+			"quadratic formula",
+			`<math display="block"><mi>x</mi><mo>=</mo><mfrac><mrow><mo>−</mo><mi>b</mi><mo>±</mo><msqrt><msup><mi>b</mi><mn>2</mn></msup><mo>−</mo><mn>4</mn><mi>a</mi><mi>c</mi></msqrt></mrow><mrow><mn>2</mn><mi>a</mi></mrow></mfrac></math>`,
+			`$$x=\frac{-b\pm\sqrt{b^2-4ac}}{2a}$$`,
+		},
+		{
+			"sum with limits",
+			`<math><munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><msub><mi>x</mi><mi>i</mi></msub></math>`,
+			`$\sum_{i=1}^n x_i$`,
+		},
+		{
+			"greek and functions",
+			`<math><mi>sin</mi><mo>&#x2061;</mo><mi>θ</mi><mo>+</mo><mi>α</mi><mi>x</mi></math>`,
+			`$\sin\theta+\alpha x$`,
+		},
+		{
+			"accent and root",
+			`<math><mover><mi>v</mi><mo>→</mo></mover><mo>=</mo><mroot><mi>x</mi><mn>3</mn></mroot></math>`,
+			`$\vec{v}=\sqrt[3]{x}$`,
+		},
+		{
+			"matrix",
+			`<math><mfenced><mtable><mtr><mtd><mn>1</mn></mtd><mtd><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd><mtd><mn>1</mn></mtd></mtr></mtable></mfenced></math>`,
+			`$(\begin{matrix}1 & 0 \\ 0 & 1\end{matrix})$`,
+		},
+		{
+			"tex annotation preferred",
+			`<math><semantics><mrow><mi>E</mi><mo>=</mo><mi>m</mi><msup><mi>c</mi><mn>2</mn></msup></mrow><annotation encoding="application/x-tex">E = mc^2</annotation></semantics></math>`,
+			`$E = mc^2$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body>" + tt.math + "</body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_KaTeX(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>Energy is <span class="katex"><span class="katex-mathml"><math><semantics><mrow><mi>E</mi><mo>=</mo><mi>m</mi><msup><mi>c</mi><mn>2</mn></msup></mrow><annotation encoding="application/x-tex">E=mc^2</annotation></semantics></math></span><span class="katex-html" aria-hidden="true"><span class="base"><span class="mord mathnormal">E</span><span class="mrel">=</span><span class="mord mathnormal">m</span><span class="mord"><span class="mord mathnormal">c</span><span class="msupsub">2</span></span></span></span></span></p>
+		<span class="katex-display"><span class="katex"><span class="katex-mathml"><math display="block"><semantics><mrow><mi>a</mi></mrow><annotation encoding="application/x-tex">\int_0^1 x\,dx</annotation></semantics></math></span><span class="katex-html" aria-hidden="true">∫01xdx</span></span></span>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if !strings.Contains(result, "Energy is $E=mc^2$") {
+		t.Errorf("Expected inline KaTeX as TeX, got: %q", result)
+	}
+	if !strings.Contains(result, `$$\int_0^1 x\,dx$$`) {
+		t.Errorf("Expected display KaTeX as TeX, got: %q", result)
+	}
+	if strings.Contains(result, "mc2") || strings.Contains(result, "∫01") {
+		t.Errorf("KaTeX layout spans should not render, got: %q", result)
+	}
+}
+
+func TestHTMLToMarkdown_MathJax(t *testing.T) {
+	input := []byte(`<html><body>
+		<p>Inline <span class="MathJax_Preview">a^2</span><span class="MathJax" id="MathJax-Element-1-Frame"><nobr>a2</nobr></span><script type="math/tex" id="MathJax-Element-1">a^2</script></p>
+		<div class="MathJax_Display"><span class="MathJax">b2</span></div><script type="math/tex; mode=display">b^2</script>
+		<mjx-container class="MathJax" jax="CHTML" display="true"><mjx-math><mjx-mi>c</mjx-mi></mjx-math><mjx-assistive-mml><math display="block"><msup><mi>c</mi><mn>2</mn></msup></math></mjx-assistive-mml></mjx-container>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	for _, e := range []string{"Inline $a^2$", "$$b^2$$", "$$c^2$$"} {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected %q, got: %q", e, result)
+		}
+	}
+	if strings.Contains(result, "a2") || strings.Contains(result, "b2") {
+		t.Errorf("MathJax output spans should not render, got: %q", result)
+	}
+}
+
+func TestHTMLToMarkdown_InlineMathSpacing(t *testing.T) {
+	input := []byte(`<html><body><p>Let<math><mi>x</mi></math>be real.</p></body></html>`)
+
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{DialectExtended, "Let $x$ be real."},
+		{DialectCommonMark, "Let `x` be real."},
+		{DialectPlain, "Let x be real."},
+	}
+	for _, tt := range tests {
+		result, _ := HTMLToMarkdown(input, StripConfig{Dialect: tt.dialect})
+		if result != tt.expected {
+			t.Errorf("Dialect %d: expected %q, got: %q", tt.dialect, tt.expected, result)
+		}
+	}
+}
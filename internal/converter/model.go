@@ -0,0 +1,224 @@
+package converter
+
+// The document model sits between the HTML tree and the output. The walker
+// (mdContext) builds a Document from html.Node, passes (see passes.go) clean it
+// up, and renderers such as markdownRenderer turn it into text. Nodes are
+// dialect independent: syntax is the renderer's business.
+
+// Node is an element of the document model
+type Node interface {
+	node()
+}
+
+// branch holds the children of a container node
+type branch struct {
+	Children []Node
+}
+
+func (b *branch) nodes() *[]Node { return &b.Children }
+
+// container is implemented by every node with children
+type container interface {
+	Node
+	nodes() *[]Node
+}
+
+// Block nodes
+
+// Document is the root of a converted page
+type Document struct{ branch }
+
+// Section is a sectioning element (section, article, main), or a group of
+// nodes such as one moved comment thread
+type Section struct {
+	ID string
+	branch
+}
+
+// Heading is an h1-h6 at its normalized level. Levels past 6 have no markdown
+// heading and render as bold text.
+type Heading struct {
+	Level int
+	ID    string // Anchor, "" when the heading has none
+	branch
+}
+
+// Title is a standalone label line the page did not mark up as a heading: a
+// tab or accordion name, a state section title. Level 0 renders as bold text.
+type Title struct {
+	Level int
+	Text  string
+}
+
+type Paragraph struct{ branch }
+
+// Quote is a blockquote or address
+type Quote struct{ branch }
+
+type List struct {
+	Ordered bool
+	branch
+}
+
+// ListItem carries its position: Depth is the nesting level from 1 and Number
+// the item's number in an ordered list (0 for bullets)
+type ListItem struct {
+	Depth  int
+	Number int
+	branch
+}
+
+// Table holds TableRow and TableCaption nodes among its children. Title is a
+// caption handed down from an enclosing figure when the table has none.
+type Table struct {
+	Title string
+	branch
+}
+
+type TableCaption struct{ branch }
+
+// TableRow is a row of TableCell nodes; Header is set for rows in <thead> or
+// made of <th> cells only
+type TableRow struct {
+	Header bool
+	branch
+}
+
+type TableCell struct {
+	Header bool
+	branch
+}
+
+// CodeBlock is preformatted text; its Text children keep their whitespace.
+// Lang is the language named by a language-* class, "" when unknown.
+type CodeBlock struct {
+	Lang string
+	branch
+}
+
+type ThematicBreak struct{}
+
+// Figure is figure content with its caption. Caption is empty when it was
+// handed to a table inside the figure.
+type Figure struct {
+	Caption string
+	branch
+}
+
+// Form is a summary of a <form> and the fields a user can fill in
+type Form struct {
+	Name   string
+	Method string
+	Action string
+	Fields []FormField
+}
+
+type FormField struct {
+	Label       string
+	Kind        string // Input type: text, email, select, multi-select, radio, submit, ...
+	Required    bool
+	Placeholder string
+	Options     []string
+}
+
+// QA is a question and answer pair; the children are the answer
+type QA struct {
+	Question string
+	branch
+}
+
+// Footnotes is the definitions block at the end of a document; its children
+// are Footnote nodes
+type Footnotes struct{ branch }
+
+type Footnote struct {
+	Label string
+	branch
+}
+
+// Comments is the trailing comments section of CommentsSeparate; each child
+// is one thread
+type Comments struct{ branch }
+
+// Inline nodes
+
+// Text is literal text, already whitespace-normalized outside code blocks
+type Text struct {
+	Text string
+}
+
+// Format is inline or block formatting rendered by its dialect's wrap rule
+// (strong, em, mark, sub, dl, details, ...). Tag names the rule.
+type Format struct {
+	Tag string
+	branch
+}
+
+// Code is inline code
+type Code struct{ branch }
+
+// Link is a hyperlink. Without Href it renders just its text.
+type Link struct {
+	Href string
+	branch
+}
+
+// Image is an image with its text alternative ("" when it has none)
+type Image struct {
+	Alt string
+	Src string
+}
+
+type LineBreak struct{}
+
+// Math is a formula as TeX
+type Math struct {
+	TeX     string
+	Display bool
+}
+
+type FootnoteRef struct {
+	Label string
+}
+
+func (*Document) node()      {}
+func (*Section) node()       {}
+func (*Heading) node()       {}
+func (*Title) node()         {}
+func (*Paragraph) node()     {}
+func (*Quote) node()         {}
+func (*List) node()          {}
+func (*ListItem) node()      {}
+func (*Table) node()         {}
+func (*TableCaption) node()  {}
+func (*TableRow) node()      {}
+func (*TableCell) node()     {}
+func (*CodeBlock) node()     {}
+func (*ThematicBreak) node() {}
+func (*Figure) node()        {}
+func (*Form) node()          {}
+func (*QA) node()            {}
+func (*Footnotes) node()     {}
+func (*Footnote) node()      {}
+func (*Comments) node()      {}
+func (*Text) node()          {}
+func (*Format) node()        {}
+func (*Code) node()          {}
+func (*Link) node()          {}
+func (*Image) node()         {}
+func (*LineBreak) node()     {}
+func (*Math) node()          {}
+func (*FootnoteRef) node()   {}
+
+// walkModel calls fn for n and its descendants in document order. Returning
+// false from fn skips the node's children.
+func walkModel(n Node, fn func(Node) bool) {
+	if !fn(n) {
+		return
+	}
+	if c, ok := n.(container); ok {
+		for _, child := range *c.nodes() {
+			walkModel(child, fn)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// NoscriptMode controls whether <noscript> fallback content is rendered.
+// For client-rendered pages the noscript block is often the only server-rendered
+// description of the page.
+type NoscriptMode int
+
+const (
+	NoscriptDrop   NoscriptMode = iota // Always strip noscript (default)
+	NoscriptAuto                       // Render noscript when the main content is (nearly) empty
+	NoscriptRender                     // Always render noscript
+)
+
+// Main content with less visible text than this counts as "nearly empty" in auto mode
+const noscriptMinContentLen = 200
+
+// Per-page override: <meta name="llm-noscript" content="render|auto|drop">
+const noscriptMetaName = "llm-noscript"
+
+var noscriptModeNames = map[string]NoscriptMode{
+	"drop":   NoscriptDrop,
+	"auto":   NoscriptAuto,
+	"render": NoscriptRender,
+}
+
+// shouldRenderNoscript resolves the noscript mode for a document. A page
+// annotation takes precedence over the configured mode; auto mode renders
+// noscript only when the main content area is empty or nearly empty.
+func shouldRenderNoscript(doc *html.Node, mode NoscriptMode, stripSet map[string]bool) bool {
+	if meta := findMeta(doc, noscriptMetaName); meta != "" {
+		if m, ok := noscriptModeNames[strings.ToLower(strings.TrimSpace(meta))]; ok {
+			mode = m
+		}
+	}
+
+	switch mode {
+	case NoscriptRender:
+		return true
+	case NoscriptAuto:
+		root := findMainContent(doc)
+		if root == nil {
+			return true
+		}
+		return contentTextLen(root, stripSet) < noscriptMinContentLen
+	}
+	return false
+}
+
+// renderNoscript renders the fallback content of a <noscript>. The parser keeps
+// noscript content as raw text (scripting is on), so it is re-parsed with
+// scripting disabled. Blocks with no text (tracking pixels, GTM iframes) are skipped.
+func (ctx *mdContext) renderNoscript(n *html.Node) {
+	if n.Parent != nil && n.Parent.Data == "head" {
+		return
+	}
+
+	var raw strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			raw.WriteString(c.Data)
+		}
+	}
+
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragmentWithOptions(strings.NewReader(raw.String()), context, html.ParseOptionEnableScripting(false))
+	if err != nil {
+		return
+	}
+
+	// Attach the parsed nodes so ancestor lookups (labels, styles) work
+	holder := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, node := range nodes {
+		holder.AppendChild(node)
+	}
+	if textContent(holder) == "" {
+		return
+	}
+
+	ctx.text("\n")
+	ctx.children(holder)
+	ctx.text("\n\n")
+}
+
+// findMainContent returns the <main> (or role="main") element, falling back to <body>
+func findMainContent(doc *html.Node) *html.Node {
+	var main, body *html.Node
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if main != nil {
+			return
+		}
+		if n.Type == html.ElementNode {
+			if effectiveTag(n) == "main" {
+				main = n
+				return
+			}
+			if n.Data == "body" && body == nil {
+				body = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	if main != nil {
+		return main
+	}
+	return body
+}
+
+// contentTextLen counts the visible text under n, ignoring stripped elements
+// and noscript itself
+func contentTextLen(n *html.Node, stripSet map[string]bool) int {
+	total := 0
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				total += len(strings.TrimSpace(c.Data))
+			case html.ElementNode:
+				if c.Data == "noscript" || c.Data == "template" || shouldStrip(c, stripSet) || hasAttr(c, "data-llm", "drop") {
+					continue
+				}
+				f(c)
+			}
+		}
+	}
+	f(n)
+	return total
+}
+
+// findMeta returns the content of <meta name=name>, or ""
+func findMeta(doc *html.Node, name string) string {
+	var content string
+	var f func(*html.Node) bool
+	f = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.Data == "meta" && strings.EqualFold(getAttr(n, "name"), name) {
+			content = getAttr(n, "content")
+			return true
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if f(c) {
+				return true
+			}
+		}
+		return false
+	}
+	f(doc)
+	return content
+}
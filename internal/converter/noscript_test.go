@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+const csrPage = `<html><head>
+	%s
+	<noscript><img height="1" width="1" src="https://tracker.example/px"></noscript>
+</head><body>
+	<div id="root"></div>
+	<noscript><h1>Acme Widget</h1><p>The Acme Widget costs $20 and ships worldwide.</p></noscript>
+	<script src="/bundle.js"></script>
+</body></html>`
+
+func TestHTMLToMarkdown_NoscriptModes(t *testing.T) {
+	longContent := strings.Repeat("Server rendered content. ", 20)
+
+	tests := []struct {
+		name     string
+		meta     string
+		body     string
+		mode     NoscriptMode
+		expected bool
+	}{
+		{"drop by default", "", "", NoscriptDrop, false},
+		{"auto with empty root", "", "", NoscriptAuto, true},
+		{"auto with content", "", `<main><p>` + longContent + `</p></main>`, NoscriptAuto, false},
+		{"render forced", "", `<main><p>` + longContent + `</p></main>`, NoscriptRender, true},
+		{"meta forces render", `<meta name="llm-noscript" content="render">`, "", NoscriptDrop, true},
+		{"meta forces drop", `<meta name="llm-noscript" content="drop">`, "", NoscriptAuto, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := strings.Replace(csrPage, "%s", tt.meta, 1)
+			page = strings.Replace(page, `<div id="root"></div>`, `<div id="root">`+tt.body+`</div>`, 1)
+
+			result, _ := HTMLToMarkdown([]byte(page), StripConfig{Noscript: tt.mode})
+			rendered := strings.Contains(result, "# Acme Widget") && strings.Contains(result, "costs $20")
+			if rendered != tt.expected {
+				t.Errorf("Expected noscript rendered=%v, got: %s", tt.expected, result)
+			}
+			if strings.Contains(result, "<h1>") || strings.Contains(result, "[Image]") {
+				t.Errorf("Noscript should render as markdown without tracking pixels, got: %s", result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_NoscriptKeep(t *testing.T) {
+	input := []byte(`<html><body><p>Content</p><noscript data-llm="keep"><p>Enable JavaScript for the calculator.</p></noscript></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if !strings.Contains(result, "Enable JavaScript for the calculator.") || strings.Contains(result, "<p>") {
+		t.Errorf("Kept noscript should render as markdown, got: %s", result)
+	}
+}
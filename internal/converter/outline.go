@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// OutlineMode controls whether the heading outline is added to the markdown
+type OutlineMode int
+
+const (
+	OutlineNone    OutlineMode = iota // Markdown only (default); the outline is still in Result.Outline
+	OutlinePrepend                    // A "## Contents" list before the markdown
+	OutlineOnly                       // The contents list instead of the markdown
+)
+
+// OutlineEntry is a heading of the converted document
+type OutlineEntry struct {
+	Level  int    // Markdown level, after normalization
+	Text   string // Heading text as rendered
+	ID     string // Anchor, "" when the heading has none
+	Tokens int    // Estimated tokens of the section, subsections included
+}
+
+// outline lists the headings of a document with the line each renders to,
+// for sizeSections
+func (r *markdownRenderer) outline(doc *Document) ([]OutlineEntry, []string) {
+	var entries []OutlineEntry
+	var lines []string
+	walkModel(doc, func(n Node) bool {
+		h, ok := n.(*Heading)
+		if !ok {
+			return true
+		}
+		if line := r.headingLine(h); line != "" {
+			text := strings.TrimSpace(r.render(h.Children))
+			entries = append(entries, OutlineEntry{Level: h.Level, Text: text, ID: h.ID})
+			lines = append(lines, line)
+		}
+		return true
+	})
+	return entries, lines
+}
+
+// estimateTokens approximates the token count of markdown at four characters per token
+func estimateTokens(md string) int {
+	return (utf8.RuneCountInString(md) + 3) / 4
+}
+
+// sizeSections fills in the token estimates of the outline from the final
+// markdown. lines holds the rendered line of each heading; a section runs to
+// the next heading of the same or a higher level. Headings that did not make
+// it into md (screened, or moved by capture) keep a zero estimate.
+func sizeSections(md string, outline []OutlineEntry, lines []string) {
+	starts := make([]int, len(outline))
+	from := 0
+	for i, line := range lines {
+		starts[i] = -1
+		var at int
+		if strings.HasPrefix(md[from:], line+"\n") || md[from:] == line {
+			at = from
+		} else if j := strings.Index(md[from:], "\n"+line+"\n"); j >= 0 {
+			at = from + j + 1
+		} else if strings.HasSuffix(md[from:], "\n"+line) {
+			at = len(md) - len(line)
+		} else {
+			continue
+		}
+		starts[i] = at
+		from = at + len(line)
+	}
+
+	for i := range outline {
+		if starts[i] < 0 {
+			continue
+		}
+		end := len(md)
+		for j := i + 1; j < len(outline); j++ {
+			if starts[j] >= 0 && outline[j].Level <= outline[i].Level {
+				end = starts[j]
+				break
+			}
+		}
+		outline[i].Tokens = estimateTokens(strings.TrimSpace(md[starts[i]:end]))
+	}
+}
+
+// FormatTOC renders an outline as a nested markdown list, linking headings
+// that have an anchor and noting section sizes where known
+func FormatTOC(outline []OutlineEntry) string {
+	if len(outline) == 0 {
+		return ""
+	}
+	top := outline[0].Level
+	for _, e := range outline {
+		top = min(top, e.Level)
+	}
+
+	var b strings.Builder
+	for _, e := range outline {
+		b.WriteString(strings.Repeat("  ", e.Level-top))
+		b.WriteString("- ")
+		if e.ID != "" {
+			b.WriteString("[" + e.Text + "](#" + e.ID + ")")
+		} else {
+			b.WriteString(e.Text)
+		}
+		if e.Tokens > 0 {
+			b.WriteString(" (~" + itoa(e.Tokens) + " tokens)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvert_Outline(t *testing.T) {
+	input := []byte(`<html><body>
+		<h1 id="guide">Guide</h1>
+		<h2>Setup</h2>
+		<section id="usage"><h2>Usage</h2><h3 id="api">The <em>API</em></h3></section>
+	</body></html>`)
+	result, err := Convert(input, StripConfig{})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	expected := []OutlineEntry{
+		{Level: 1, Text: "Guide", ID: "guide", Tokens: 11},
+		{Level: 2, Text: "Setup", ID: "setup", Tokens: 2},
+		{Level: 2, Text: "Usage", ID: "usage", Tokens: 6},
+		{Level: 3, Text: "The *API*", ID: "api", Tokens: 4},
+	}
+	if !reflect.DeepEqual(result.Outline, expected) {
+		t.Errorf("Expected %+v, got: %+v", expected, result.Outline)
+	}
+}
+
+func TestFormatTOC(t *testing.T) {
+	outline := []OutlineEntry{
+		{Level: 2, Text: "Install", ID: "install"},
+		{Level: 3, Text: "Linux"},
+		{Level: 2, Text: "Usage", ID: "usage"},
+	}
+	expected := "- [Install](#install)\n  - Linux\n- [Usage](#usage)\n"
+	if got := FormatTOC(outline); got != expected {
+		t.Errorf("Expected %q, got: %q", expected, got)
+	}
+	outline[0].Tokens = 120
+	if got := FormatTOC(outline[:1]); got != "- [Install](#install) (~120 tokens)\n" {
+		t.Errorf("Expected token estimate, got: %q", got)
+	}
+	if got := FormatTOC(nil); got != "" {
+		t.Errorf("Expected empty TOC, got: %q", got)
+	}
+}
+
+func TestConvert_OutlineModes(t *testing.T) {
+	input := []byte(`<html><body>
+		<h1>Guide</h1>
+		<p>Read this first.</p>
+		<h2>Setup</h2>
+		<p>Install the package and run it.</p>
+	</body></html>`)
+	toc := "- [Guide](#guide) (~17 tokens)\n  - [Setup](#setup) (~11 tokens)"
+	body := "# Guide\n\nRead this first.\n\n## Setup\n\nInstall the package and run it."
+
+	tests := []struct {
+		name     string
+		mode     OutlineMode
+		expected string
+	}{
+		{"none", OutlineNone, body},
+		{"prepend", OutlinePrepend, "## Contents\n\n" + toc + "\n\n" + body},
+		{"only", OutlineOnly, toc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := HTMLToMarkdown(input, StripConfig{Outline: tt.mode})
+			if err != nil {
+				t.Fatalf("HTMLToMarkdown failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Getting Started":      "getting-started",
+		"  What's new in 2.0?": "what-s-new-in-2-0",
+		"Überblick":            "überblick",
+		"---":                  "",
+	}
+	for input, expected := range tests {
+		if got := slugify(input); got != expected {
+			t.Errorf("slugify(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
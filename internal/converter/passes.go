@@ -0,0 +1,211 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// optimize runs the model passes. They clean the document once for every
+// renderer, so outputs other than markdown get the same content.
+func optimize(doc *Document) {
+	dropNoise(doc)
+	pruneEmpty(doc)
+	fixFragmentedLists(doc, 0)
+	mergeText(doc)
+}
+
+// Containers that render nothing when they have no children. Table cells
+// stay so columns line up; list items, code and links still write markers.
+func prunable(n Node) bool {
+	switch n.(type) {
+	case *Paragraph, *Quote, *Format, *Section, *TableCaption:
+		return true
+	}
+	return false
+}
+
+// pruneEmpty removes empty text and the containers left without children
+func pruneEmpty(c container) {
+	kids := c.nodes()
+	kept := (*kids)[:0]
+	for _, n := range *kids {
+		if sub, ok := n.(container); ok {
+			pruneEmpty(sub)
+			if prunable(n) && len(*sub.nodes()) == 0 {
+				continue
+			}
+		}
+		if t, ok := n.(*Text); ok && t.Text == "" {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	*kids = kept
+}
+
+// dropNoise removes lines of attribution or copyright text (see
+// noisePatterns). A line is a run of inline nodes ended by a <br> or a block,
+// so a noise line goes whether it is a paragraph, a list item, a quote or a
+// line within one. Blocks left without content go with it. Code keeps its
+// text, and headings and tables their structure.
+func dropNoise(c container) {
+	kids := c.nodes()
+	var kept []Node
+	start := 0
+	for i := 0; i <= len(*kids); i++ {
+		if i < len(*kids) && isInline((*kids)[i]) {
+			if _, br := (*kids)[i].(*LineBreak); !br {
+				continue
+			}
+		}
+		line := (*kids)[start:i]
+		noise := isNoiseLine(line)
+		if !noise {
+			kept = append(kept, line...)
+		}
+		start = i + 1
+
+		if i == len(*kids) {
+			// A dropped last line leaves the <br> before it dangling
+			if noise && len(kept) > 0 {
+				if _, br := kept[len(kept)-1].(*LineBreak); br {
+					kept = kept[:len(kept)-1]
+				}
+			}
+			break
+		}
+		switch n := (*kids)[i].(type) {
+		case *LineBreak:
+			if !noise {
+				kept = append(kept, n)
+			}
+		case *CodeBlock, *Heading, *Table:
+			kept = append(kept, n)
+		case container:
+			had := len(*n.nodes()) > 0
+			dropNoise(n)
+			if !had || len(*n.nodes()) > 0 {
+				kept = append(kept, n)
+			}
+		default:
+			kept = append(kept, n)
+		}
+	}
+	*kids = kept
+}
+
+// isNoiseLine reports whether a line of inline nodes reads as attribution or
+// copyright text. Text spanning several lines is content, not a credit.
+func isNoiseLine(line []Node) bool {
+	text := lineText(line)
+	if text == "" || strings.Contains(text, "\n") {
+		return false
+	}
+	lower := strings.ToLower(text)
+	for _, pattern := range noisePatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineText returns the text of a run of inline nodes
+func lineText(line []Node) string {
+	var b strings.Builder
+	for _, n := range line {
+		walkModel(n, func(n Node) bool {
+			if t, ok := n.(*Text); ok {
+				b.WriteString(t.Text)
+			}
+			return true
+		})
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// fixFragmentedLists rebuilds numbered lists that pages lay out as separate
+// blocks: a paragraph holding just a number (1-99) and the paragraphs up to
+// the next such number become one item, "1. part - part". depth is the list
+// depth of the container.
+func fixFragmentedLists(c container, depth int) {
+	kids := *c.nodes()
+	var kept []Node
+	var list *List // The list the previous fragment went into
+	for i := 0; i < len(kids); i++ {
+		switch n := kids[i].(type) {
+		case *ListItem:
+			fixFragmentedLists(n, n.Depth)
+		case *CodeBlock, *Table:
+			// Numbers in code and table cells are content
+		case container:
+			fixFragmentedLists(n, depth)
+		}
+
+		num, ok := fragmentNumber(kids[i])
+		if !ok {
+			kept = append(kept, kids[i])
+			list = nil
+			continue
+		}
+		item := &ListItem{Depth: depth + 1, Number: num}
+		for i+1 < len(kids) {
+			p, ok := kids[i+1].(*Paragraph)
+			if !ok {
+				break
+			}
+			if _, next := fragmentNumber(p); next {
+				break
+			}
+			if len(item.Children) > 0 {
+				item.Children = append(item.Children, &Text{Text: " - "})
+			}
+			item.Children = append(item.Children, p.Children...)
+			i++
+		}
+		if len(item.Children) == 0 {
+			kept = append(kept, kids[i])
+			list = nil
+			continue
+		}
+		if list == nil {
+			list = &List{Ordered: true}
+			kept = append(kept, list)
+		}
+		list.Children = append(list.Children, item)
+	}
+	*c.nodes() = kept
+}
+
+// fragmentNumber returns the number of a paragraph that holds nothing else
+func fragmentNumber(n Node) (int, bool) {
+	p, ok := n.(*Paragraph)
+	if !ok {
+		return 0, false
+	}
+	text := lineText(p.Children)
+	if !isStandaloneNumber(text) {
+		return 0, false
+	}
+	num, err := strconv.Atoi(text)
+	return num, err == nil
+}
+
+// mergeText joins adjacent text nodes that pruning brought together
+func mergeText(c container) {
+	kids := c.nodes()
+	kept := (*kids)[:0]
+	for _, n := range *kids {
+		if sub, ok := n.(container); ok {
+			mergeText(sub)
+		}
+		if t, ok := n.(*Text); ok && len(kept) > 0 {
+			if prev, ok := kept[len(kept)-1].(*Text); ok {
+				prev.Text += t.Text
+				continue
+			}
+		}
+		kept = append(kept, n)
+	}
+	*kids = kept
+}
@@ -0,0 +1,87 @@
+package converter
+
+import "testing"
+
+func TestOptimize(t *testing.T) {
+	doc := &Document{}
+	doc.Children = []Node{
+		&Paragraph{branch{[]Node{&Text{"Keep "}, &Format{Tag: "em"}, &Text{""}, &Text{"me"}}}},
+		&Paragraph{branch{[]Node{&Text{"Photo by Jane Doe"}}}},
+		&Quote{branch{[]Node{&Text{""}}}},
+		&Section{ID: "empty", branch: branch{[]Node{&Paragraph{}}}},
+		&Table{branch: branch{[]Node{&TableRow{branch: branch{[]Node{&TableCell{}}}}}}},
+	}
+
+	optimize(doc)
+
+	if len(doc.Children) != 2 {
+		t.Fatalf("Expected the paragraph and the table to remain, got %d nodes", len(doc.Children))
+	}
+	p, ok := doc.Children[0].(*Paragraph)
+	if !ok || len(p.Children) != 1 {
+		t.Fatalf("Expected the paragraph's text merged into one node, got %#v", doc.Children[0])
+	}
+	if text := p.Children[0].(*Text).Text; text != "Keep me" {
+		t.Errorf("Expected %q, got %q", "Keep me", text)
+	}
+	row := doc.Children[1].(*Table).Children[0].(*TableRow)
+	if len(row.Children) != 1 {
+		t.Errorf("Expected the empty table cell to stay, got %d cells", len(row.Children))
+	}
+}
+
+func TestDropNoise_MultilineKept(t *testing.T) {
+	doc := &Document{}
+	doc.Children = []Node{&Paragraph{branch{[]Node{&Text{"Photo by Jane Doe\nand a longer story"}}}}}
+
+	dropNoise(doc)
+
+	if len(doc.Children) != 1 {
+		t.Errorf("Expected a multi-line paragraph to stay")
+	}
+}
+
+func TestDropNoise_Lines(t *testing.T) {
+	doc := &Document{}
+	doc.Children = []Node{
+		&List{branch: branch{[]Node{
+			&ListItem{Depth: 1, branch: branch{[]Node{&Text{"Photo by Jane Doe"}}}},
+			&ListItem{Depth: 1, branch: branch{[]Node{&Text{"Real item"}}}},
+		}}},
+		&Quote{branch{[]Node{&Format{Tag: "em", branch: branch{[]Node{&Text{"© Acme"}}}}}}},
+		&Paragraph{branch{[]Node{&Text{"Keep"}, &LineBreak{}, &Text{"Credit: Jane"}}}},
+		&CodeBlock{branch: branch{[]Node{&Text{"// Source: upstream"}}}},
+	}
+
+	dropNoise(doc)
+
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected the list, paragraph and code block to remain, got %d nodes", len(doc.Children))
+	}
+	if items := doc.Children[0].(*List).Children; len(items) != 1 {
+		t.Errorf("Expected the noise list item dropped, got %d items", len(items))
+	}
+	if p := doc.Children[1].(*Paragraph); len(p.Children) != 1 {
+		t.Errorf("Expected the noise line and its <br> dropped, got %#v", p.Children)
+	}
+}
+
+func TestFixFragmentedLists(t *testing.T) {
+	para := func(s string) Node { return &Paragraph{branch{[]Node{&Text{s}}}} }
+	doc := &Document{}
+	doc.Children = []Node{para("1"), para("First"), para("2"), para("Second"), para("more"), &ThematicBreak{}, para("3")}
+
+	fixFragmentedLists(doc, 0)
+
+	if len(doc.Children) != 3 {
+		t.Fatalf("Expected a list, the break and the lone number, got %d nodes", len(doc.Children))
+	}
+	list, ok := doc.Children[0].(*List)
+	if !ok || !list.Ordered || len(list.Children) != 2 {
+		t.Fatalf("Expected an ordered list of two items, got %#v", doc.Children[0])
+	}
+	second := list.Children[1].(*ListItem)
+	if second.Number != 2 || second.Depth != 1 || lineText(second.Children) != "Second - more" {
+		t.Errorf("Expected item 2 \"Second - more\", got %d %q", second.Number, lineText(second.Children))
+	}
+}
@@ -0,0 +1,354 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrInvalidSelector is returned when StripConfig.Select cannot be parsed
+var ErrInvalidSelector = errors.New("invalid selector")
+
+// A minimal CSS selector engine for StripConfig.Select. Supported: type and
+// universal selectors, #id, .class, attribute selectors ([attr], =, ~=, ^=,
+// $=, *=, |=), descendant and child combinators, and comma-separated groups.
+// Pseudo-classes and sibling combinators are not.
+
+type attrMatch struct {
+	key, op, val string
+}
+
+type compoundSelector struct {
+	tag     string // "" matches any element
+	id      string
+	classes []string
+	attrs   []attrMatch
+}
+
+type selectorStep struct {
+	compoundSelector
+	combinator byte // Relation to the previous step: ' ' (descendant) or '>' (child)
+}
+
+type complexSelector []selectorStep
+
+// Bounds on StripConfig.Select, which may come from a query string
+const (
+	maxSelectorLength    = 512
+	maxSelectorCompounds = 32 // Across all groups
+)
+
+// selectNodes returns the elements matching any of the selectors, in document
+// order. Matches nested inside another match are left out so nothing renders twice.
+func selectNodes(doc *html.Node, groups []complexSelector) []*html.Node {
+	var nodes []*html.Node
+	memo := make(map[matchKey]bool)
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if matchesAny(c, groups, memo) {
+				nodes = append(nodes, c)
+				continue
+			}
+			f(c)
+		}
+	}
+	f(doc)
+	return nodes
+}
+
+// matchKey is one element tried against one step of one selector group
+type matchKey struct {
+	n           *html.Node
+	group, step int
+}
+
+func matchesAny(n *html.Node, groups []complexSelector, memo map[matchKey]bool) bool {
+	for g, sel := range groups {
+		if sel.matches(n, g, len(sel)-1, memo) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether n matches step i and the steps before it match its
+// ancestors. Results are memoised: without that, descendant combinators
+// backtrack over every ancestor for every step, which is exponential in the
+// selector length.
+func (sel complexSelector) matches(n *html.Node, group, i int, memo map[matchKey]bool) bool {
+	key := matchKey{n, group, i}
+	if found, ok := memo[key]; ok {
+		return found
+	}
+	found := sel.matchStep(n, group, i, memo)
+	memo[key] = found
+	return found
+}
+
+func (sel complexSelector) matchStep(n *html.Node, group, i int, memo map[matchKey]bool) bool {
+	if !sel[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if sel.matches(p, group, i-1, memo) {
+			return true
+		}
+		if sel[i].combinator == '>' {
+			return false
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) match(n *html.Node) bool {
+	if c.tag != "" && c.tag != n.Data {
+		return false
+	}
+	if c.id != "" && getAttr(n, "id") != c.id {
+		return false
+	}
+	for _, class := range c.classes {
+		if !hasClass(n, class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		if !a.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrMatch) match(n *html.Node) bool {
+	if !hasAttrKey(n, a.key) {
+		return false
+	}
+	v := getAttr(n, a.key)
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return v == a.val
+	case "~=":
+		for _, word := range strings.Fields(v) {
+			if word == a.val {
+				return true
+			}
+		}
+		return false
+	case "^=":
+		return a.val != "" && strings.HasPrefix(v, a.val)
+	case "$=":
+		return a.val != "" && strings.HasSuffix(v, a.val)
+	case "*=":
+		return a.val != "" && strings.Contains(v, a.val)
+	case "|=":
+		return v == a.val || strings.HasPrefix(v, a.val+"-")
+	}
+	return false
+}
+
+// parseSelector parses a comma-separated selector group
+func parseSelector(s string) ([]complexSelector, error) {
+	if len(s) > maxSelectorLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidSelector, maxSelectorLength)
+	}
+	p := &selectorParser{s: s}
+	var groups []complexSelector
+	compounds := 0
+	for {
+		sel, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		if compounds += len(sel); compounds > maxSelectorCompounds {
+			return nil, p.errorf("more than %d compound selectors", maxSelectorCompounds)
+		}
+		groups = append(groups, sel)
+		p.skipSpace()
+		if p.eof() {
+			return groups, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		p.i++
+	}
+}
+
+type selectorParser struct {
+	s string
+	i int
+}
+
+func (p *selectorParser) eof() bool  { return p.i >= len(p.s) }
+func (p *selectorParser) peek() byte { return p.s[p.i] }
+
+func (p *selectorParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d in %q", ErrInvalidSelector, fmt.Sprintf(format, args...), p.i, p.s)
+}
+
+// skipSpace skips whitespace and reports whether there was any
+func (p *selectorParser) skipSpace() bool {
+	start := p.i
+	for !p.eof() && strings.IndexByte(" \t\n\r\f", p.peek()) >= 0 {
+		p.i++
+	}
+	return p.i > start
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	p.skipSpace()
+	var sel complexSelector
+	var combinator byte
+	for {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, selectorStep{compoundSelector: c, combinator: combinator})
+
+		spaced := p.skipSpace()
+		switch {
+		case p.eof() || p.peek() == ',':
+			return sel, nil
+		case p.peek() == '>':
+			combinator = '>'
+			p.i++
+			p.skipSpace()
+		case spaced:
+			combinator = ' '
+		default:
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var c compoundSelector
+	start := p.i
+	if !p.eof() && p.peek() == '*' {
+		p.i++
+	} else if tag := p.ident(); tag != "" {
+		c.tag = strings.ToLower(tag)
+	}
+
+	for !p.eof() {
+		switch p.peek() {
+		case '#':
+			p.i++
+			if c.id = p.ident(); c.id == "" {
+				return c, p.errorf("expected id")
+			}
+		case '.':
+			p.i++
+			class := p.ident()
+			if class == "" {
+				return c, p.errorf("expected class name")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			p.i++
+			a, err := p.parseAttr()
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+		default:
+			if p.i == start {
+				return c, p.errorf("expected selector")
+			}
+			return c, nil
+		}
+	}
+	if p.i == start {
+		return c, p.errorf("expected selector")
+	}
+	return c, nil
+}
+
+func (p *selectorParser) parseAttr() (attrMatch, error) {
+	var a attrMatch
+	p.skipSpace()
+	if a.key = strings.ToLower(p.ident()); a.key == "" {
+		return a, p.errorf("expected attribute name")
+	}
+	p.skipSpace()
+	if p.eof() {
+		return a, p.errorf("unterminated attribute selector")
+	}
+	if p.peek() != ']' {
+		for _, op := range []string{"=", "~=", "^=", "$=", "*=", "|="} {
+			if strings.HasPrefix(p.s[p.i:], op) {
+				a.op = op
+				p.i += len(op)
+				break
+			}
+		}
+		if a.op == "" {
+			return a, p.errorf("unexpected %q", p.peek())
+		}
+		p.skipSpace()
+		val, err := p.value()
+		if err != nil {
+			return a, err
+		}
+		a.val = val
+		p.skipSpace()
+	}
+	if p.eof() || p.peek() != ']' {
+		return a, p.errorf("expected ]")
+	}
+	p.i++
+	return a, nil
+}
+
+// value reads a quoted string or an identifier
+func (p *selectorParser) value() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected value")
+	}
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		if v := p.ident(); v != "" {
+			return v, nil
+		}
+		return "", p.errorf("expected value")
+	}
+	end := strings.IndexByte(p.s[p.i+1:], quote)
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	v := p.s[p.i+1 : p.i+1+end]
+	p.i += end + 2
+	return v, nil
+}
+
+// ident reads a CSS identifier. Backslash escapes the next character.
+func (p *selectorParser) ident() string {
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '\\' && p.i+1 < len(p.s):
+			b.WriteByte(p.s[p.i+1])
+			p.i += 2
+		case c == '-' || c == '_' || c >= 0x80 || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			b.WriteByte(c)
+			p.i++
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
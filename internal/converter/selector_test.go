@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const selectorPage = `<html><body>
+	<main id="content">
+		<article class="post featured" data-kind="guide"><h2>Guide</h2><p class="lead">Start here.</p></article>
+		<article class="post"><h2>News</h2><p>Released.</p><div><p class="lead">Nested lead.</p></div></article>
+	</main>
+	<aside><p class="lead">Sidebar lead.</p></aside>
+	<a href="https://example.com/docs" lang="en-US">Docs</a>
+</body></html>`
+
+func TestSelectNodes(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected []string // Text of matched elements
+	}{
+		{"article", []string{"Guide Start here.", "News Released. Nested lead."}},
+		{"#content > article.featured", []string{"Guide Start here."}},
+		{".post.featured h2", []string{"Guide"}},
+		{"article > .lead", []string{"Start here."}},
+		{"main .lead", []string{"Start here.", "Nested lead."}},
+		{"[data-kind=guide] p, aside p", []string{"Start here.", "Sidebar lead."}},
+		{`a[href^="https://"][href$='/docs']`, []string{"Docs"}},
+		{"[class~=featured]", []string{"Guide Start here."}},
+		{"[lang|=en]", []string{"Docs"}},
+		{"[href*=example]", []string{"Docs"}},
+		{"main, main p", []string{"Guide Start here. News Released. Nested lead."}},
+		{"*[data-kind]", []string{"Guide Start here."}},
+		{"table", nil},
+	}
+
+	doc, err := html.Parse(strings.NewReader(selectorPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			groups, err := parseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("parseSelector failed: %v", err)
+			}
+			var got []string
+			for _, n := range selectNodes(doc, groups) {
+				got = append(got, textContent(n))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected %q, got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseSelector_Invalid(t *testing.T) {
+	for _, sel := range []string{"", "div >", "p,", "[href", "[href=]", `[href="x]`, "a:hover", "#", "div ~ p"} {
+		if _, err := parseSelector(sel); !errors.Is(err, ErrInvalidSelector) {
+			t.Errorf("Expected ErrInvalidSelector for %q, got: %v", sel, err)
+		}
+	}
+}
+
+func TestParseSelector_Limits(t *testing.T) {
+	long := strings.Repeat("div ", maxSelectorCompounds) + "p"
+	if _, err := parseSelector(long); !errors.Is(err, ErrInvalidSelector) {
+		t.Errorf("Expected ErrInvalidSelector for %d compounds, got: %v", maxSelectorCompounds+1, err)
+	}
+	if _, err := parseSelector(strings.Repeat("a", maxSelectorLength+1)); !errors.Is(err, ErrInvalidSelector) {
+		t.Errorf("Expected ErrInvalidSelector for a long selector, got: %v", err)
+	}
+}
+
+func TestSelectNodes_DeepPage(t *testing.T) {
+	// Descendant combinators over a deep page must not backtrack exponentially
+	page := strings.Repeat("<div>", 200) + "<p>Deep</p>" + strings.Repeat("</div>", 200)
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := parseSelector("span" + strings.Repeat(" div", maxSelectorCompounds-2) + " p")
+	if err != nil {
+		t.Fatalf("parseSelector failed: %v", err)
+	}
+
+	done := make(chan []*html.Node)
+	go func() { done <- selectNodes(doc, groups) }()
+	select {
+	case nodes := <-done:
+		if len(nodes) != 0 {
+			t.Errorf("Expected no match, got %d", len(nodes))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Selector matching did not finish")
+	}
+}
+
+func TestHTMLToMarkdown_Select(t *testing.T) {
+	result, err := HTMLToMarkdown([]byte(selectorPage), StripConfig{Select: "article.featured, aside"})
+	if err != nil {
+		t.Fatalf("HTMLToMarkdown failed: %v", err)
+	}
+	// The aside is stripped from the page, so selecting it does not bring it back
+	if result != "## Guide\n\nStart here." {
+		t.Errorf("Expected selected subtrees only, got: %q", result)
+	}
+
+	if _, err := HTMLToMarkdown([]byte(selectorPage), StripConfig{Select: "table"}); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound for no match, got: %v", err)
+	}
+}
+
+func TestHTMLToMarkdown_SelectExcluded(t *testing.T) {
+	input := []byte(`<html><body>
+		<script>var apiKey="sk-123";</script>
+		<div data-llm="drop"><p class="s">Draft</p></div>
+		<p class="s" style="display:none">Hidden</p>
+		<p class="s">Public</p>
+	</body></html>`)
+
+	if result, err := HTMLToMarkdown(input, StripConfig{Select: "script"}); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound for a stripped element, got %q, %v", result, err)
+	}
+	result, err := HTMLToMarkdown(input, StripConfig{Select: ".s", SkipHidden: true})
+	if err != nil || result != "Public" {
+		t.Errorf("Expected only the rendered match, got %q, %v", result, err)
+	}
+}
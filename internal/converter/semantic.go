@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// renderAbbr expands an abbreviation on first use: "API (Application
+// Programming Interface)". Later uses print just the abbreviation.
+func (ctx *mdContext) renderAbbr(n *html.Node) {
+	text := textContent(n)
+	expansion := collapseSpace(getAttr(n, "title"))
+	ctx.text(" ")
+	ctx.children(n)
+	if text != "" && expansion != "" && !strings.EqualFold(expansion, text) && !ctx.abbrSeen[text] {
+		if ctx.abbrSeen == nil {
+			ctx.abbrSeen = make(map[string]bool)
+		}
+		ctx.abbrSeen[text] = true
+		ctx.text(" (" + expansion + ")")
+	}
+	ctx.text(" ")
+}
+
+// renderValue writes an element's text followed by its machine-readable value
+// (<time datetime>, <data value>) when the two differ: "March 3 (2024-03-03)"
+func (ctx *mdContext) renderValue(n *html.Node, attr string) {
+	value := strings.TrimSpace(getAttr(n, attr))
+	text := textContent(n)
+	ctx.text(" ")
+	defer ctx.text(" ")
+	switch {
+	case value == "" || value == text:
+		ctx.children(n)
+	case text == "":
+		ctx.text(value)
+	default:
+		ctx.children(n)
+		ctx.text(" (" + value + ")")
+	}
+}
+
+// renderGauge writes <meter> and <progress> as "Label: 70%". A progress bar
+// without a value is "Progress: indeterminate".
+func (ctx *mdContext) renderGauge(n *html.Node) {
+	label := ctx.accessibleName(n)
+	if label == "" {
+		label = "Progress"
+		if n.Data == "meter" {
+			label = "Meter"
+		}
+	}
+
+	value, ok := floatAttr(n, "value")
+	if !ok {
+		if n.Data == "progress" {
+			ctx.text(" " + label + ": indeterminate ")
+		} else if text := textContent(n); text != "" {
+			ctx.text(" " + label + ": " + text + " ")
+		}
+		return
+	}
+
+	lo := 0.0
+	if n.Data == "meter" {
+		if v, ok := floatAttr(n, "min"); ok {
+			lo = v
+		}
+	}
+	hi := 1.0
+	if v, ok := floatAttr(n, "max"); ok && v > lo {
+		hi = v
+	}
+	value = math.Max(lo, math.Min(hi, value))
+	pct := math.Round((value - lo) / (hi - lo) * 100)
+
+	ctx.text(" " + label + ": " + strconv.FormatFloat(pct, 'f', -1, 64) + "% ")
+}
+
+func floatAttr(n *html.Node, key string) (float64, bool) {
+	if !hasAttrKey(n, key) {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(getAttr(n, key)), 64)
+	return v, err == nil
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestHTMLToMarkdown_SemanticValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"abbr expands on first use",
+			`<p>The <abbr title="Application Programming Interface">API</abbr> is stable. Call the <abbr title="Application Programming Interface">API</abbr> or <abbr>CLI</abbr> tools.</p>`,
+			"The API (Application Programming Interface) is stable. Call the API or CLI tools.",
+		},
+		{
+			"abbr title matching text",
+			`<p><abbr title="NASA">NASA</abbr></p>`,
+			"NASA",
+		},
+		{
+			"time with datetime",
+			`<p>Published <time datetime="2024-03-03T10:00Z">March 3</time></p>`,
+			"Published March 3 (2024-03-03T10:00Z)",
+		},
+		{
+			"time without datetime",
+			`<p><time>2024-03-03</time></p>`,
+			"2024-03-03",
+		},
+		{
+			"data value",
+			`<p><data value="SKU-1234">Trail Shoe</data></p>`,
+			"Trail Shoe (SKU-1234)",
+		},
+		{
+			"progress",
+			`<p><progress value="70" max="100"></progress></p>`,
+			"Progress: 70%",
+		},
+		{
+			"progress indeterminate",
+			`<p><progress></progress></p>`,
+			"Progress: indeterminate",
+		},
+		{
+			"labelled meter",
+			`<p><meter aria-label="Disk usage" min="0" max="200" value="50">50 of 200</meter></p>`,
+			"Disk usage: 25%",
+		},
+		{
+			"meter without value",
+			`<p><meter>High</meter></p>`,
+			"Meter: High",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := HTMLToMarkdown([]byte("<html><body>"+tt.input+"</body></html>"), StripConfig{})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
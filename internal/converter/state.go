@@ -0,0 +1,506 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// StateExtractor recognises a framework's embedded page-state payload (Next.js
+// __NEXT_DATA__, Nuxt __NUXT_DATA__, Remix __remixContext) and decodes it into
+// plain JSON values: map[string]any, []any, string, json.Number, bool and nil.
+type StateExtractor interface {
+	Name() string
+	Match(script *html.Node) bool
+	Extract(script *html.Node) (any, error)
+}
+
+// StateSection turns the value at a JSON path of an extracted payload into a
+// markdown section. Path segments are separated by dots; numeric segments index
+// arrays and "*" maps over every element, e.g. "props.pageProps.reviews.*.body".
+// Keys containing dots are written quoted in brackets, as in
+// `state.loaderData["routes/blog.$slug"].title`.
+type StateSection struct {
+	Title     string // Section heading; omitted when empty
+	Path      string
+	Extractor string // Only apply to payloads from this extractor; empty matches all
+}
+
+// StateConfig enables extraction of embedded SPA state
+type StateConfig struct {
+	Extractors []StateExtractor // Extractors to try; nil uses DefaultStateExtractors
+	Sections   []StateSection   // Paths rendered as content; nothing is extracted without them
+}
+
+// DefaultStateExtractors covers the common SSR frameworks
+var DefaultStateExtractors = []StateExtractor{
+	JSONScriptExtractor{ExtractorName: "next", ID: "__NEXT_DATA__"},
+	NuxtExtractor{},
+	AssignmentExtractor{ExtractorName: "remix", Global: "window.__remixContext"},
+}
+
+// Rendered state is cut off below this nesting depth
+const maxStateDepth = 4
+
+var errNoPayload = errors.New("no state payload in script")
+
+// JSONScriptExtractor matches a <script type="application/json"> with a given id
+type JSONScriptExtractor struct {
+	ExtractorName string
+	ID            string
+}
+
+func (e JSONScriptExtractor) Name() string { return e.ExtractorName }
+
+func (e JSONScriptExtractor) Match(script *html.Node) bool {
+	return getAttr(script, "id") == e.ID
+}
+
+func (e JSONScriptExtractor) Extract(script *html.Node) (any, error) {
+	return decodeJSON(scriptText(script))
+}
+
+// AssignmentExtractor matches an inline script that assigns a JSON literal to a
+// global, as in `window.__remixContext = {...};`.
+type AssignmentExtractor struct {
+	ExtractorName string
+	Global        string
+}
+
+func (e AssignmentExtractor) Name() string { return e.ExtractorName }
+
+func (e AssignmentExtractor) Match(script *html.Node) bool {
+	return getAttr(script, "src") == "" && strings.Contains(scriptText(script), e.Global)
+}
+
+func (e AssignmentExtractor) Extract(script *html.Node) (any, error) {
+	text := scriptText(script)
+	_, rest, ok := strings.Cut(text, e.Global)
+	if !ok {
+		return nil, errNoPayload
+	}
+	rest = strings.TrimSpace(rest)
+	rest, ok = strings.CutPrefix(rest, "=")
+	if !ok {
+		return nil, errNoPayload
+	}
+
+	// Decode just the first value; whatever follows (";", more statements) is ignored
+	dec := json.NewDecoder(strings.NewReader(rest))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NuxtExtractor decodes Nuxt 3's __NUXT_DATA__ payload, which is serialised with
+// devalue: a flat JSON array where objects and arrays hold indexes into the
+// array rather than values, and tagged arrays (["Reactive", 3]) wrap values.
+type NuxtExtractor struct{}
+
+func (NuxtExtractor) Name() string { return "nuxt" }
+
+func (NuxtExtractor) Match(script *html.Node) bool {
+	return getAttr(script, "id") == "__NUXT_DATA__"
+}
+
+func (NuxtExtractor) Extract(script *html.Node) (any, error) {
+	raw, err := decodeJSON(scriptText(script))
+	if err != nil {
+		return nil, err
+	}
+	values, ok := raw.([]any)
+	if !ok || len(values) == 0 {
+		return nil, errNoPayload
+	}
+	return reviveDevalue(values), nil
+}
+
+// reviveDevalue rebuilds the value graph of a devalue payload. Cycles resolve to nil.
+func reviveDevalue(values []any) any {
+	resolved := make(map[int]any)
+	visiting := make(map[int]bool)
+
+	var revive func(idx int) any
+	revive = func(idx int) any {
+		if idx < 0 || idx >= len(values) {
+			return nil // Negative indexes encode undefined, NaN, holes and friends
+		}
+		if v, ok := resolved[idx]; ok {
+			return v
+		}
+		if visiting[idx] {
+			return nil
+		}
+		visiting[idx] = true
+		defer delete(visiting, idx)
+
+		var out any
+		switch v := values[idx].(type) {
+		case map[string]any:
+			obj := make(map[string]any, len(v))
+			for k, ref := range v {
+				obj[k] = revive(devalueIndex(ref))
+			}
+			out = obj
+		case []any:
+			if tag, ok := firstString(v); ok {
+				out = reviveTagged(tag, v[1:], revive)
+			} else {
+				arr := make([]any, len(v))
+				for i, ref := range v {
+					arr[i] = revive(devalueIndex(ref))
+				}
+				out = arr
+			}
+		default:
+			out = v
+		}
+		resolved[idx] = out
+		return out
+	}
+	return revive(0)
+}
+
+// reviveTagged unwraps devalue's tagged values and Nuxt's reactivity wrappers
+func reviveTagged(tag string, args []any, revive func(int) any) any {
+	switch tag {
+	case "Reactive", "ShallowReactive", "Ref", "ShallowRef", "NuxtError", "Island", "Object":
+		if len(args) > 0 {
+			return revive(devalueIndex(args[0]))
+		}
+	case "EmptyRef", "EmptyShallowRef", "Date", "BigInt", "RegExp", "URL":
+		if len(args) > 0 {
+			if s, ok := args[0].(string); ok {
+				return s
+			}
+		}
+	case "Set":
+		arr := make([]any, len(args))
+		for i, ref := range args {
+			arr[i] = revive(devalueIndex(ref))
+		}
+		return arr
+	case "Map":
+		obj := make(map[string]any, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			key := revive(devalueIndex(args[i]))
+			obj[scalarString(key)] = revive(devalueIndex(args[i+1]))
+		}
+		return obj
+	}
+	return nil
+}
+
+func devalueIndex(ref any) int {
+	if n, ok := ref.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return int(i)
+		}
+	}
+	return -1
+}
+
+func firstString(v []any) (string, bool) {
+	if len(v) == 0 {
+		return "", false
+	}
+	s, ok := v[0].(string)
+	return s, ok
+}
+
+// renderState renders the configured sections found in a state script. It
+// reports false when no extractor claims the script.
+func (ctx *mdContext) renderState(script *html.Node) bool {
+	extractors := ctx.state.Extractors
+	if extractors == nil {
+		extractors = DefaultStateExtractors
+	}
+
+	for _, ext := range extractors {
+		if !ext.Match(script) {
+			continue
+		}
+		payload, err := ext.Extract(script)
+		if err != nil {
+			return true // A recognised but unreadable payload is still just a script
+		}
+		for _, section := range ctx.state.Sections {
+			if section.Extractor != "" && section.Extractor != ext.Name() {
+				continue
+			}
+			value, ok := lookupPath(payload, section.Path)
+			if !ok || isEmptyValue(value) {
+				continue
+			}
+			ctx.text("\n")
+			if section.Title != "" {
+				ctx.add(&Title{Level: 2, Text: section.Title})
+			}
+			ctx.renderStateValue(value, 0)
+			ctx.text("\n\n")
+		}
+		return true
+	}
+	return false
+}
+
+// renderStateValue writes a JSON value as markdown: strings as paragraphs (HTML
+// strings are converted like page content), arrays as lists and objects as
+// "key: value" lists.
+func (ctx *mdContext) renderStateValue(v any, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch val := v.(type) {
+	case string:
+		if depth == 0 && looksLikeHTML(val) {
+			ctx.renderHTMLString(val)
+			return
+		}
+		ctx.text(collapseSpace(val))
+	case []any:
+		for _, item := range val {
+			if isEmptyValue(item) {
+				continue
+			}
+			ctx.text("\n" + indent + "- ")
+			ctx.renderStateItem(item, depth)
+		}
+	case map[string]any:
+		for _, key := range sortedKeys(val) {
+			if isEmptyValue(val[key]) {
+				continue
+			}
+			ctx.text("\n" + indent + "- " + key + ": ")
+			ctx.renderStateItem(val[key], depth)
+		}
+	default:
+		ctx.text(scalarString(val))
+	}
+}
+
+// renderStateItem writes a list item value: scalars inline, containers nested,
+// and objects of scalars folded onto one line.
+func (ctx *mdContext) renderStateItem(v any, depth int) {
+	switch val := v.(type) {
+	case map[string]any:
+		if flat := flatObject(val); flat != "" {
+			ctx.text(flat)
+			return
+		}
+	case string:
+		ctx.text(collapseSpace(val))
+		return
+	}
+	if depth+1 >= maxStateDepth {
+		ctx.text("...")
+		return
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		ctx.renderStateValue(v, depth+1)
+	default:
+		ctx.text(scalarString(v))
+	}
+}
+
+// renderHTMLString converts an HTML string from a payload (CMS bodies are often
+// stored this way) with the same rules as the page itself
+func (ctx *mdContext) renderHTMLString(s string) {
+	holder := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(s), holder)
+	if err != nil {
+		ctx.text(collapseSpace(s))
+		return
+	}
+	for _, node := range nodes {
+		holder.AppendChild(node)
+	}
+	ctx.children(holder)
+}
+
+// lookupPath resolves a state path in a decoded JSON value
+func lookupPath(v any, path string) (any, bool) {
+	segments, ok := pathSegments(path)
+	if !ok {
+		return nil, false
+	}
+	return lookupSegments(v, segments)
+}
+
+func lookupSegments(v any, segments []string) (any, bool) {
+	if len(segments) == 0 {
+		return v, true
+	}
+	head, rest := segments[0], segments[1:]
+
+	switch val := v.(type) {
+	case map[string]any:
+		child, ok := val[head]
+		if !ok {
+			return nil, false
+		}
+		return lookupSegments(child, rest)
+	case []any:
+		if head == "*" {
+			var out []any
+			for _, item := range val {
+				if found, ok := lookupSegments(item, rest); ok {
+					out = append(out, found)
+				}
+			}
+			return out, len(out) > 0
+		}
+		i, err := strconv.Atoi(head)
+		if err != nil || i < 0 || i >= len(val) {
+			return nil, false
+		}
+		return lookupSegments(val[i], rest)
+	}
+	return nil, false
+}
+
+// pathSegments splits a state path into its segments. Segments are separated
+// by dots or written in brackets; a bracketed segment may be a double-quoted
+// string for keys that contain dots, as in `loaderData["routes/blog.$slug"]`.
+// It reports false for a malformed path.
+func pathSegments(path string) ([]string, bool) {
+	var segments []string
+	for path != "" {
+		var segment string
+		if rest, ok := strings.CutPrefix(path, "["); ok {
+			end := strings.IndexByte(rest, ']')
+			if strings.HasPrefix(rest, `"`) {
+				end = quotedEnd(rest)
+				if end < 0 || !strings.HasPrefix(rest[end:], "]") {
+					return nil, false
+				}
+			}
+			if end < 0 {
+				return nil, false
+			}
+			segment = rest[:end]
+			if strings.HasPrefix(segment, `"`) {
+				unquoted, err := strconv.Unquote(segment)
+				if err != nil {
+					return nil, false
+				}
+				segment = unquoted
+			}
+			path = rest[end+1:]
+		} else {
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segment, path = path[:end], path[end:]
+		}
+		segments = append(segments, segment)
+
+		if rest, ok := strings.CutPrefix(path, "."); ok {
+			if rest == "" {
+				return nil, false
+			}
+			path = rest
+		}
+	}
+	return segments, true
+}
+
+// quotedEnd returns the index just past the double-quoted string s starts
+// with, or -1 if it is not closed
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// flatObject folds an object whose values are all scalars into "k: v, k2: v2"
+func flatObject(obj map[string]any) string {
+	var parts []string
+	for _, key := range sortedKeys(obj) {
+		switch val := obj[key].(type) {
+		case map[string]any, []any:
+			return ""
+		case nil:
+		default:
+			if s := scalarString(val); s != "" {
+				parts = append(parts, key+": "+s)
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func scalarString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return collapseSpace(val)
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func isEmptyValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	}
+	return false
+}
+
+func looksLikeHTML(s string) bool {
+	i := strings.IndexByte(s, '<')
+	return i >= 0 && strings.IndexByte(s[i:], '>') > 0
+}
+
+// sortedKeys returns map keys in a stable order so output is deterministic
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func scriptText(script *html.Node) string {
+	var b strings.Builder
+	for c := script.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
+
+func decodeJSON(s string) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
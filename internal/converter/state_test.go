@@ -0,0 +1,145 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestHTMLToMarkdown_NextData(t *testing.T) {
+	input := []byte(`<html><body>
+		<div id="__next"></div>
+		<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"product":{
+			"name":"Trail Shoe",
+			"description":"<p>Lightweight <strong>trail</strong> runner.</p>",
+			"specs":{"weight":"240g","drop":"6mm"},
+			"reviews":[{"author":"Ann","rating":5},{"author":"Bo","rating":4}]
+		}}}}</script>
+	</body></html>`)
+
+	cfg := StripConfig{State: StateConfig{Sections: []StateSection{
+		{Title: "Product", Path: "props.pageProps.product.name"},
+		{Path: "props.pageProps.product.description"},
+		{Title: "Specs", Path: "props.pageProps.product.specs"},
+		{Title: "Reviewers", Path: "props.pageProps.product.reviews.*.author"},
+		{Title: "Reviews", Path: "props.pageProps.product.reviews"},
+		{Title: "Missing", Path: "props.pageProps.nothing"},
+	}}}
+	result, _ := HTMLToMarkdown(input, cfg)
+
+	expected := []string{
+		"## Product\n\nTrail Shoe",
+		"Lightweight **trail** runner.",
+		"## Specs\n\n- drop: 6mm\n- weight: 240g",
+		"## Reviewers\n\n- Ann\n- Bo",
+		"- author: Ann, rating: 5",
+	}
+	for _, e := range expected {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected %q, got: %s", e, result)
+		}
+	}
+	if strings.Contains(result, "Missing") || strings.Contains(result, "pageProps") {
+		t.Errorf("Unmatched paths and raw JSON should not render, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_NuxtData(t *testing.T) {
+	// devalue payload for {data: {page: {title: "Nuxt page", tags: ["a", "b"]}}}
+	input := []byte(`<html><body>
+		<script type="application/json" id="__NUXT_DATA__">[["Reactive",1],{"data":2},{"page":3},{"title":4,"tags":5},"Nuxt page",[6,7],"a","b"]</script>
+	</body></html>`)
+
+	cfg := StripConfig{State: StateConfig{Sections: []StateSection{
+		{Title: "Title", Path: "data.page.title", Extractor: "nuxt"},
+		{Title: "Tags", Path: "data.page.tags"},
+	}}}
+	result, _ := HTMLToMarkdown(input, cfg)
+
+	if !strings.Contains(result, "## Title\n\nNuxt page") || !strings.Contains(result, "## Tags\n\n- a\n- b") {
+		t.Errorf("Expected revived Nuxt payload, got: %s", result)
+	}
+}
+
+func TestHTMLToMarkdown_RemixContext(t *testing.T) {
+	input := []byte(`<html><body>
+		<script>window.__remixContext = {"state":{"loaderData":{"routes/blog":{"title":"Remix post"}}}};__remixContext.p = null;</script>
+	</body></html>`)
+
+	cfg := StripConfig{State: StateConfig{Sections: []StateSection{
+		{Title: "Post", Path: "state.loaderData.routes/blog.title", Extractor: "remix"},
+		{Title: "Wrong extractor", Path: "state.loaderData.routes/blog.title", Extractor: "next"},
+	}}}
+	result, _ := HTMLToMarkdown(input, cfg)
+
+	if result != "## Post\n\nRemix post" {
+		t.Errorf("Expected Remix section only, got: %q", result)
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	var payload any = map[string]any{
+		"loaderData": map[string]any{
+			"routes/blog.$slug": map[string]any{"title": "Flat route"},
+			"root":              map[string]any{"tags": []any{"a", "b"}},
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected any
+	}{
+		{`loaderData["routes/blog.$slug"].title`, "Flat route"},
+		{`loaderData["routes/blog.$slug"]["title"]`, "Flat route"},
+		{`loaderData.root.tags[1]`, "b"},
+		{`loaderData.root.tags.0`, "a"},
+		{`loaderData.routes/blog.$slug.title`, nil},
+		{`loaderData["routes/blog.$slug"`, nil},
+		{`loaderData.`, nil},
+	}
+	for _, tt := range tests {
+		got, ok := lookupPath(payload, tt.path)
+		if tt.expected == nil {
+			if ok {
+				t.Errorf("%s: expected no value, got %v", tt.path, got)
+			}
+			continue
+		}
+		if !ok || got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.expected, got)
+		}
+	}
+}
+
+func TestHTMLToMarkdown_StateDisabled(t *testing.T) {
+	input := []byte(`<html><body><p>Shell</p><script id="__NEXT_DATA__" type="application/json">{"props":{"x":"secret"}}</script></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if result != "Shell" {
+		t.Errorf("State scripts should be stripped without sections, got: %q", result)
+	}
+}
+
+type customExtractor struct{}
+
+func (customExtractor) Name() string { return "custom" }
+func (customExtractor) Match(script *html.Node) bool {
+	return getAttr(script, "type") == "application/x-page-state"
+}
+func (customExtractor) Extract(script *html.Node) (any, error) {
+	return decodeJSON(scriptText(script))
+}
+
+func TestHTMLToMarkdown_CustomStateExtractor(t *testing.T) {
+	input := []byte(`<html><body><script type="application/x-page-state">{"headline":"Custom state"}</script></body></html>`)
+	cfg := StripConfig{State: StateConfig{
+		Extractors: []StateExtractor{customExtractor{}},
+		Sections:   []StateSection{{Path: "headline"}},
+	}}
+	result, _ := HTMLToMarkdown(input, cfg)
+
+	if result != "Custom state" {
+		t.Errorf("Expected custom extractor output, got: %q", result)
+	}
+}
@@ -0,0 +1,305 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNotStreamable is returned by ConvertStream for options that need the
+// whole document: Section, Select and Outline
+var ErrNotStreamable = errors.New("option needs the whole document and cannot be streamed")
+
+// Containers ConvertStream passes through instead of buffering: their
+// children convert one at a time. Every other element is buffered until it
+// ends, so memory is bounded by the largest such element: a <table>, <ul> or
+// <form> is held and converted whole, however large.
+var streamContainers = map[string]bool{
+	"html": true, "head": true, "body": true,
+	"div": true, "main": true, "section": true, "article": true,
+}
+
+// Elements without an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// Pending HTML is converted once it holds this much and no element is open.
+// Small blocks share a conversion; a container boundary converts what is
+// pending whatever its size.
+const streamChunk = 32 << 10
+
+// ConvertStream converts HTML read from r to markdown written to w as it goes,
+// without building the whole document. It tokenizes the input, passes through
+// containers such as <div> and <article>, and converts the elements inside
+// them in chunks of about streamChunk bytes, so memory stays bounded by the
+// largest element outside those containers rather than the page. A page that
+// is mostly one such element, say a single large <table>, is buffered and
+// converted as a whole.
+//
+// Each chunk is converted in the context of its open containers, so
+// stripping, hidden content, section anchors and comment threads behave as in
+// HTMLToMarkdown. Heading normalization and anchor numbering carry across
+// chunks. What needs the whole page works within one chunk only: footnotes
+// whose definitions are in another chunk stay plain references, and tab
+// panels are matched to their tab list only when both are in one chunk.
+// Chunks are separated by a blank line, so loose text split across containers
+// becomes separate paragraphs.
+//
+// It returns the warnings of every block once the input is consumed.
+func ConvertStream(r io.Reader, w io.Writer, stripConfig StripConfig) ([]Warning, error) {
+	if stripConfig.Section != "" || stripConfig.Select != "" || stripConfig.Outline != OutlineNone {
+		return nil, ErrNotStreamable
+	}
+	s := &streamer{w: w, cfg: stripConfig}
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return s.warnings(), err
+			}
+			if err := s.flush(); err != nil {
+				return s.warnings(), err
+			}
+			return s.warnings(), s.finish()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if err := s.startTag(z, tt == html.SelfClosingTagToken); err != nil {
+				return s.warnings(), err
+			}
+		case html.EndTagToken:
+			if err := s.endTag(z); err != nil {
+				return s.warnings(), err
+			}
+		case html.TextToken:
+			if s.elem == "" && s.buf.Len() == 0 && len(bytes.TrimSpace(z.Raw())) == 0 {
+				continue
+			}
+			s.buf.Write(z.Raw())
+			if err := s.flushFull(); err != nil {
+				return s.warnings(), err
+			}
+		}
+		// Comments and doctypes are not content
+	}
+}
+
+// streamer holds the state of ConvertStream between tokens
+type streamer struct {
+	w     io.Writer
+	cfg   StripConfig
+	open  []*openContainer // Containers passed through, outermost first
+	buf   bytes.Buffer     // Pending HTML: loose content or the element being read
+	elem  string           // Element being buffered, "" between elements
+	depth int              // Nesting of elem within itself
+	prev  *mdContext       // Context of the last block, carried into the next
+	wrote bool
+}
+
+type openContainer struct {
+	tag    html.Token
+	headed bool // A heading inside it was converted, so its id is taken
+}
+
+func (s *streamer) startTag(z *html.Tokenizer, selfClosing bool) error {
+	raw := z.Raw()
+	tok := z.Token()
+	tag := tok.Data
+
+	if s.elem != "" {
+		// <p> cannot nest: a new one ends the last
+		if tag == "p" && s.elem == "p" {
+			s.elem = ""
+			if err := s.flushFull(); err != nil {
+				return err
+			}
+			return s.startElement(tag, raw, selfClosing)
+		}
+		s.buf.Write(raw)
+		if tag == s.elem && !selfClosing {
+			s.depth++
+		}
+		return nil
+	}
+
+	if streamContainers[tag] && !selfClosing {
+		if err := s.flush(); err != nil {
+			return err
+		}
+		s.open = append(s.open, &openContainer{tag: tok})
+		return nil
+	}
+
+	return s.startElement(tag, raw, selfClosing)
+}
+
+// startElement buffers an element until its end tag. Void elements are
+// complete at once.
+func (s *streamer) startElement(tag string, raw []byte, selfClosing bool) error {
+	s.buf.Write(raw)
+	if selfClosing || voidElements[tag] {
+		return s.flushFull()
+	}
+	s.elem, s.depth = tag, 1
+	return nil
+}
+
+func (s *streamer) endTag(z *html.Tokenizer) error {
+	name, _ := z.TagName()
+	tag := string(name)
+
+	if s.elem != "" && !s.closesContainer(tag) {
+		s.buf.Write(z.Raw())
+		if tag != s.elem {
+			return nil
+		}
+		s.depth--
+		if s.depth > 0 {
+			return nil
+		}
+		s.elem = ""
+		return s.flushFull()
+	}
+
+	// A container's end tag also ends anything left open inside it
+	for i := len(s.open) - 1; i >= 0; i-- {
+		if s.open[i].tag.Data == tag {
+			if err := s.flush(); err != nil {
+				return err
+			}
+			s.open = s.open[:i]
+			return nil
+		}
+	}
+	// A stray end tag: keep it with the loose content
+	s.buf.Write(z.Raw())
+	return nil
+}
+
+// closesContainer reports whether an end tag closes an open container while
+// an element inside it is still being read (a <p> left unclosed, say)
+func (s *streamer) closesContainer(tag string) bool {
+	if tag == s.elem || !streamContainers[tag] {
+		return false
+	}
+	for _, c := range s.open {
+		if c.tag.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// flushFull converts the pending HTML once it fills a chunk and no element is
+// open
+func (s *streamer) flushFull() error {
+	if s.elem != "" || s.buf.Len() < streamChunk {
+		return nil
+	}
+	return s.flush()
+}
+
+// flush converts the pending HTML and writes its markdown
+func (s *streamer) flush() error {
+	s.elem, s.depth = "", 0
+	if len(bytes.TrimSpace(s.buf.Bytes())) == 0 {
+		s.buf.Reset()
+		return nil
+	}
+
+	// Reopen the containers so the block converts in context
+	var chunk strings.Builder
+	for _, c := range s.open {
+		tag := c.tag
+		if c.headed {
+			tag.Attr = withoutAttr(tag.Attr, "id")
+		}
+		chunk.WriteString(tag.String())
+	}
+	chunk.Write(s.buf.Bytes())
+	s.buf.Reset()
+
+	doc, err := html.Parse(strings.NewReader(chunk.String()))
+	if err != nil {
+		return err
+	}
+	model := &Document{}
+	ctx := newContext(doc, s.cfg)
+	ctx.out = &model.Children
+	s.carry(ctx)
+	ctx.walk(doc)
+	ctx.renderFootnotes()
+	optimize(model)
+
+	walkModel(model, func(n Node) bool {
+		if _, ok := n.(*Heading); ok {
+			for _, c := range s.open {
+				c.headed = true
+			}
+		}
+		return true
+	})
+	return s.write(ctx.markdown(model))
+}
+
+// carry hands the state that spans blocks from the last context to ctx
+func (s *streamer) carry(ctx *mdContext) {
+	if s.prev != nil {
+		ctx.headingState = s.prev.headingState
+		ctx.lastHeading = s.prev.lastHeading
+		ctx.anchorsUsed = s.prev.anchorsUsed
+		ctx.abbrSeen = s.prev.abbrSeen
+		ctx.warnings = s.prev.warnings
+		ctx.movedComments = s.prev.movedComments
+	}
+	s.prev = ctx
+}
+
+// finish writes the comment threads moved to the end of the document
+func (s *streamer) finish() error {
+	if s.prev == nil || len(s.prev.movedComments) == 0 {
+		return nil
+	}
+	model := &Document{}
+	s.prev.out = &model.Children
+	s.prev.renderMovedComments()
+	optimize(model)
+	return s.write(s.prev.markdown(model))
+}
+
+// write writes one block's markdown, a blank line apart from the last
+func (s *streamer) write(md string) error {
+	if md == "" {
+		return nil
+	}
+	if s.wrote {
+		md = "\n\n" + md
+	}
+	s.wrote = true
+	_, err := io.WriteString(s.w, md)
+	return err
+}
+
+func (s *streamer) warnings() []Warning {
+	if s.prev == nil {
+		return nil
+	}
+	return s.prev.warnings
+}
+
+// withoutAttr returns attrs without the attribute key
+func withoutAttr(attrs []html.Attribute, key string) []html.Attribute {
+	var kept []html.Attribute
+	for _, a := range attrs {
+		if a.Key != key {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SVGMode controls how inline <svg> is handled
+type SVGMode int
+
+const (
+	SVGDrop     SVGMode = iota // Strip svg like other default elements (default)
+	SVGDescribe                // Render "[Diagram: title — desc]"
+	SVGLabels                  // Describe, and list the <text> labels (chart axes, flowchart nodes)
+)
+
+// Text labels beyond this count are left out of the description
+const maxSVGLabels = 30
+
+// renderSVG describes an inline SVG from its title, description and text labels.
+// Purely decorative SVGs (aria-hidden with no title or description) and SVGs
+// with nothing to say are dropped.
+func (ctx *mdContext) renderSVG(n *html.Node) {
+	title := ctx.idrefText(getAttr(n, "aria-labelledby"))
+	if title == "" {
+		title = collapseSpace(getAttr(n, "aria-label"))
+	}
+	if title == "" {
+		if t := findChild(n, "title"); t != nil {
+			title = textContent(t)
+		}
+	}
+
+	desc := ctx.idrefText(getAttr(n, "aria-describedby"))
+	if desc == "" {
+		if d := findChild(n, "desc"); d != nil {
+			desc = textContent(d)
+		}
+	}
+
+	if title == "" && desc == "" && hasAttr(n, "aria-hidden", "true") {
+		return
+	}
+
+	var labels []string
+	if ctx.svgMode == SVGLabels {
+		labels = svgLabels(n)
+	}
+	if title == "" && desc == "" && len(labels) == 0 {
+		return
+	}
+
+	ctx.text("[Diagram")
+	switch {
+	case title != "" && desc != "" && desc != title:
+		ctx.text(": " + title + " — " + desc)
+	case title != "":
+		ctx.text(": " + title)
+	case desc != "":
+		ctx.text(": " + desc)
+	}
+	ctx.text("]")
+
+	if len(labels) > 0 {
+		ctx.text(" (labels: ")
+		ctx.text(strings.Join(labels, ", "))
+		ctx.text(")")
+	}
+}
+
+// svgLabels collects the distinct <text> labels of an SVG in document order
+func svgLabels(n *html.Node) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && len(labels) < maxSVGLabels; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.Data == "text" {
+				if label := textContent(c); label != "" && !seen[label] {
+					seen[label] = true
+					labels = append(labels, label)
+				}
+				continue
+			}
+			f(c)
+		}
+	}
+	f(n)
+	return labels
+}
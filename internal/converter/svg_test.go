@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+const chartSVG = `<svg viewBox="0 0 100 100">
+	<title>Quarterly revenue</title>
+	<desc>Revenue grew every quarter</desc>
+	<g><text>Q1</text><text>Q2</text><text>Q1</text></g>
+	<text><tspan>$1.2M</tspan></text>
+</svg>`
+
+func TestHTMLToMarkdown_SVGModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     SVGMode
+		expected string
+	}{
+		{"drop", SVGDrop, "Before\n\nAfter"},
+		{"describe", SVGDescribe, "Before\n\n[Diagram: Quarterly revenue — Revenue grew every quarter]\n\nAfter"},
+		{"labels", SVGLabels, "Before\n\n[Diagram: Quarterly revenue — Revenue grew every quarter] (labels: Q1, Q2, $1.2M)\n\nAfter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body><p>Before</p><p>" + chartSVG + "</p><p>After</p></body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{SVG: tt.mode})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_SVGDecorative(t *testing.T) {
+	tests := []struct {
+		name     string
+		svg      string
+		expected string
+	}{
+		{"aria-hidden without title", `<svg aria-hidden="true"><path d="M0 0"/></svg>`, ""},
+		{"no title or labels", `<svg><path d="M0 0"/></svg>`, ""},
+		{"aria-hidden with title", `<svg aria-hidden="true"><title>Logo</title></svg>`, "[Diagram: Logo]"},
+		{"role img with aria-label", `<svg role="img" aria-label="Warning"><path d="M0 0"/></svg>`, "[Diagram: Warning]"},
+		{"labels only", `<svg><text>Start</text><text>End</text></svg>`, "[Diagram] (labels: Start, End)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []byte("<html><body>" + tt.svg + "</body></html>")
+			result, _ := HTMLToMarkdown(input, StripConfig{SVG: SVGLabels})
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown_SVGIconLink(t *testing.T) {
+	input := []byte(`<html><body><a href="/cart"><svg><title>Cart</title></svg></a></body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{SVG: SVGDescribe})
+
+	if !strings.Contains(result, "[Diagram: Cart]](/cart)") {
+		t.Errorf("Expected described icon inside link, got: %s", result)
+	}
+}
@@ -0,0 +1,160 @@
+package converter
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// TableFormat selects the syntax of data tables
+type TableFormat int
+
+const (
+	TablesMarkdown TableFormat = iota // The dialect's table syntax (default)
+	TablesCSV                         // A ```csv block
+	TablesTSV                         // A ```tsv block, tab separated
+	TablesTONL                        // A ```tonl block: a header naming the columns, then one line per row
+	TablesAuto                        // Whichever of the above takes the fewest tokens
+)
+
+// TableConfig controls how tables are written. The zero value writes every
+// row in the dialect's syntax.
+type TableConfig struct {
+	Format  TableFormat
+	MaxRows int // Rows kept below the header; 0 means no limit. The rest are counted in a "N more rows" line.
+}
+
+// Compact formats TablesAuto weighs against the markdown table, in order of
+// preference when they tie
+var compactFormats = []TableFormat{TablesCSV, TablesTSV, TablesTONL}
+
+// writeTable writes a table in the configured format
+func (r *markdownRenderer) writeTable(b *strings.Builder, t *Table) {
+	switch r.tables.Format {
+	case TablesCSV, TablesTSV, TablesTONL:
+		r.writeDataTable(b, t, r.tables.Format)
+	case TablesAuto:
+		var md strings.Builder
+		r.writeMarkdownTable(&md, t)
+		best := md.String()
+		for _, format := range compactFormats {
+			var alt strings.Builder
+			r.writeDataTable(&alt, t, format)
+			if s := alt.String(); s != "" && estimateTokens(s) < estimateTokens(best) {
+				best = s
+			}
+		}
+		b.WriteString(best)
+	default:
+		r.writeMarkdownTable(b, t)
+	}
+}
+
+// writeDataTable writes a table as a fenced CSV, TSV or TONL block. The first
+// row names the columns, as in a pipe table.
+func (r *markdownRenderer) writeDataTable(b *strings.Builder, t *Table, format TableFormat) {
+	rows, more := r.limitRows(tableRows(t))
+	if len(rows) == 0 {
+		return
+	}
+	cells := r.tableCells(rows)
+	width := 0
+	for _, row := range cells {
+		width = max(width, len(row))
+	}
+	for i := range cells {
+		for len(cells[i]) < width {
+			cells[i] = append(cells[i], "")
+		}
+	}
+
+	b.WriteString("\n\n")
+	if caption := r.tableCaption(t); caption != "" {
+		b.WriteString(r.emphasis(caption) + "\n\n")
+	}
+	var block strings.Builder
+	name := "csv"
+	switch format {
+	case TablesTONL:
+		name = "tonl"
+		writeTONL(&block, cells)
+	case TablesTSV:
+		name = "tsv"
+		writeDelimited(&block, cells, '\t')
+	default:
+		writeDelimited(&block, cells, ',')
+	}
+	if r.dialect == DialectPlain {
+		b.WriteString(block.String() + "\n")
+	} else {
+		b.WriteString("```" + name + "\n" + block.String() + "```\n\n")
+	}
+	r.writeMoreRows(b, more)
+}
+
+// writeDelimited writes rows as CSV with the given separator, quoting cells
+// that contain it
+func writeDelimited(b *strings.Builder, cells [][]string, comma rune) {
+	w := csv.NewWriter(b)
+	w.Comma = comma
+	w.WriteAll(cells) // Writing to a strings.Builder cannot fail
+}
+
+// writeTONL writes rows in TONL's tabular form: the header row becomes
+// "table[rows]{col,col}:" and each other row an indented line of values
+func writeTONL(b *strings.Builder, cells [][]string) {
+	header := make([]string, len(cells[0]))
+	for i, name := range cells[0] {
+		header[i] = tonlValue(name)
+	}
+	b.WriteString("table[" + itoa(len(cells)-1) + "]{" + strings.Join(header, ",") + "}:\n")
+	for _, row := range cells[1:] {
+		values := make([]string, len(row))
+		for i, v := range row {
+			values[i] = tonlValue(v)
+		}
+		b.WriteString("  " + strings.Join(values, ", ") + "\n")
+	}
+}
+
+// tonlValue quotes a value that would otherwise end early or change meaning
+func tonlValue(s string) string {
+	if s == "" || !strings.ContainsAny(s, `,"{}:[]`) && strings.TrimSpace(s) == s {
+		return s
+	}
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// limitRows keeps header rows and the first MaxRows others, and returns how
+// many were left out
+func (r *markdownRenderer) limitRows(rows []*TableRow) ([]*TableRow, int) {
+	if r.tables.MaxRows <= 0 {
+		return rows, 0
+	}
+	var kept []*TableRow
+	data, more := 0, 0
+	for i, row := range rows {
+		// The first row is the header of a pipe table even without <th>
+		if row.Header || i == 0 {
+			kept = append(kept, row)
+			continue
+		}
+		if data == r.tables.MaxRows {
+			more++
+			continue
+		}
+		kept = append(kept, row)
+		data++
+	}
+	return kept, more
+}
+
+// writeMoreRows writes the marker of rows limitRows left out
+func (r *markdownRenderer) writeMoreRows(b *strings.Builder, more int) {
+	switch more {
+	case 0:
+	case 1:
+		b.WriteString(r.emphasis("1 more row") + "\n\n")
+	default:
+		b.WriteString(r.emphasis(itoa(more)+" more rows") + "\n\n")
+	}
+}
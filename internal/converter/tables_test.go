@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+const pricingTable = `<html><body>
+	<table>
+		<caption>Plans</caption>
+		<thead><tr><th>Plan</th><th>Price, monthly</th></tr></thead>
+		<tbody>
+			<tr><td>Basic</td><td>$10</td></tr>
+			<tr><td>Team "XL"</td><td>$30</td></tr>
+			<tr><td>Enterprise</td><td>Call us</td></tr>
+		</tbody>
+	</table>
+	<p>Prices exclude tax.</p>
+</body></html>`
+
+func TestTableFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      StripConfig
+		expected string
+	}{
+		{
+			name: "csv",
+			cfg:  StripConfig{Tables: TableConfig{Format: TablesCSV}},
+			expected: "*Plans*\n\n```csv\nPlan,\"Price, monthly\"\nBasic,$10\n\"Team \"\"XL\"\"\",$30\nEnterprise,Call us\n```\n\n" +
+				"Prices exclude tax.",
+		},
+		{
+			name: "tsv",
+			cfg:  StripConfig{Tables: TableConfig{Format: TablesTSV}},
+			expected: "*Plans*\n\n```tsv\nPlan\tPrice, monthly\nBasic\t$10\n\"Team \"\"XL\"\"\"\t$30\nEnterprise\tCall us\n```\n\n" +
+				"Prices exclude tax.",
+		},
+		{
+			name: "tonl",
+			cfg:  StripConfig{Tables: TableConfig{Format: TablesTONL}},
+			expected: "*Plans*\n\n```tonl\ntable[3]{Plan,\"Price, monthly\"}:\n  Basic, $10\n  \"Team \\\"XL\\\"\", $30\n  Enterprise, Call us\n```\n\n" +
+				"Prices exclude tax.",
+		},
+		{
+			name:     "plain text has no fences",
+			cfg:      StripConfig{Dialect: DialectPlain, Tables: TableConfig{Format: TablesCSV}},
+			expected: "Plans\n\nPlan,\"Price, monthly\"\nBasic,$10\n\"Team \"\"XL\"\"\",$30\nEnterprise,Call us\n\nPrices exclude tax.",
+		},
+		{
+			name: "row limit in GFM",
+			cfg:  StripConfig{Dialect: DialectGFM, Tables: TableConfig{MaxRows: 1}},
+			expected: "*Plans*\n\n| Plan | Price, monthly |\n| --- | --- |\n| Basic | $10 |\n\n*2 more rows*\n\n" +
+				"Prices exclude tax.",
+		},
+		{
+			name: "row limit in the extended dialect",
+			cfg:  StripConfig{Tables: TableConfig{MaxRows: 2}},
+			expected: "*Plans*\n| **Plan** | **Price, monthly** |\n| Basic | $10 |\n| Team \"XL\" | $30 |\n\n*1 more row*\n\n" +
+				"Prices exclude tax.",
+		},
+		{
+			name:     "row limit in a compact format",
+			cfg:      StripConfig{Tables: TableConfig{Format: TablesCSV, MaxRows: 1}},
+			expected: "*Plans*\n\n```csv\nPlan,\"Price, monthly\"\nBasic,$10\n```\n\n*2 more rows*\n\nPrices exclude tax.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := HTMLToMarkdown([]byte(pricingTable), tt.cfg)
+			if err != nil {
+				t.Fatalf("HTMLToMarkdown failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got: %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTableFormats_Auto(t *testing.T) {
+	// A long spec table is cheaper as delimited text than as pipes
+	var rows strings.Builder
+	for i := 0; i < 20; i++ {
+		rows.WriteString("<tr><td>Model " + itoa(i) + "</td><td>" + itoa(i*100) + " W</td><td>" + itoa(i+2) + " kg</td></tr>")
+	}
+	long := "<html><body><table><tr><th>Model</th><th>Power</th><th>Weight</th></tr>" + rows.String() + "</table></body></html>"
+
+	cfg := StripConfig{Dialect: DialectGFM, Tables: TableConfig{Format: TablesAuto}}
+	auto, err := HTMLToMarkdown([]byte(long), cfg)
+	if err != nil {
+		t.Fatalf("HTMLToMarkdown failed: %v", err)
+	}
+	pipes, _ := HTMLToMarkdown([]byte(long), StripConfig{Dialect: DialectGFM})
+	if !strings.HasPrefix(auto, "```csv\nModel,Power,Weight\n") || estimateTokens(auto) >= estimateTokens(pipes) {
+		t.Errorf("Expected a CSV block cheaper than the pipe table (~%d tokens), got ~%d: %s",
+			estimateTokens(pipes), estimateTokens(auto), auto)
+	}
+
+	// A single cell costs less as a pipe table than with a fence around it
+	single := []byte("<html><body><table><tr><td>Yes</td></tr></table></body></html>")
+	auto, _ = HTMLToMarkdown(single, cfg)
+	if expected := "| Yes |\n| --- |"; auto != expected {
+		t.Errorf("Expected the pipe table %q, got: %q", expected, auto)
+	}
+}
+
+func TestTableFormats_JSONRowLimit(t *testing.T) {
+	doc := convertJSON(t, pricingTable, StripConfig{Tables: TableConfig{MaxRows: 2}})
+
+	table := doc.Blocks[0]
+	if table.Type != "table" || len(table.Rows) != 2 || table.More != 1 {
+		t.Errorf("Expected two rows and one more, got: %+v", table)
+	}
+}
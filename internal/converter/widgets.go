@@ -0,0 +1,199 @@
+package converter
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Carousel markup of the common libraries (Slick, Swiper, Bootstrap, Owl,
+// Glide, Splide). Libraries that loop clone slides at both ends of the track.
+var (
+	carouselSlideClasses = []string{
+		"slick-slide", "swiper-slide", "carousel-item", "owl-item", "glide__slide", "splide__slide",
+	}
+	carouselCloneClasses = []string{
+		"slick-cloned", "swiper-slide-duplicate", "cloned", "glide__slide--clone", "splide__slide--clone",
+	}
+	carouselControlClasses = []string{
+		"slick-arrow", "slick-prev", "slick-next", "slick-dots",
+		"swiper-button-prev", "swiper-button-next", "swiper-pagination", "swiper-scrollbar",
+		"carousel-control-prev", "carousel-control-next", "carousel-indicators",
+		"owl-nav", "owl-dots", "glide__arrows", "glide__bullets", "splide__arrows", "splide__pagination",
+	}
+)
+
+// Controlled elements with these roles are popups, not accordion panels
+var popupRoles = map[string]bool{"menu": true, "listbox": true, "dialog": true, "tree": true, "grid": true}
+
+// widgets records the ARIA tab and accordion patterns of a document. Panels
+// render in place under their label whether or not they are currently shown.
+type widgets struct {
+	tablists   map[*html.Node]bool   // Tab strips, replaced by the panel labels
+	tabPanels  map[*html.Node]string // Tab panel -> tab label
+	accordions map[*html.Node]bool   // Accordion panels
+	triggers   map[*html.Node]bool   // Accordion buttons outside a heading, rendered as a label
+}
+
+// collectWidgets finds tab and accordion panels. It returns nil when the
+// document has none.
+func (ctx *mdContext) collectWidgets() *widgets {
+	w := &widgets{
+		tablists:   make(map[*html.Node]bool),
+		tabPanels:  make(map[*html.Node]string),
+		accordions: make(map[*html.Node]bool),
+		triggers:   make(map[*html.Node]bool),
+	}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch {
+			case hasAttr(c, "role", "tab"):
+				if panel := ctx.byID(getAttr(c, "aria-controls")); panel != nil {
+					if label := ctx.accessibleName(c); label != "" {
+						w.tabPanels[panel] = label
+					}
+					if list := tablistOf(c); list != nil {
+						w.tablists[list] = true
+					}
+				}
+			case hasAttr(c, "role", "tabpanel"):
+				if _, ok := w.tabPanels[c]; !ok {
+					if label := ctx.idrefText(getAttr(c, "aria-labelledby")); label != "" {
+						w.tabPanels[c] = label
+					}
+				}
+				for _, id := range strings.Fields(getAttr(c, "aria-labelledby")) {
+					if tab := ctx.byID(id); tab != nil && hasAttr(tab, "role", "tab") {
+						if list := tablistOf(tab); list != nil {
+							w.tablists[list] = true
+						}
+					}
+				}
+			case hasAttrKey(c, "aria-expanded") && !hasAttrKey(c, "aria-haspopup"):
+				panel := ctx.byID(getAttr(c, "aria-controls"))
+				if panel == nil || popupRoles[getAttr(panel, "role")] || isAncestor(panel, c) {
+					break
+				}
+				w.accordions[panel] = true
+				if !inHeading(c) {
+					w.triggers[c] = true
+				}
+			}
+			f(c)
+		}
+	}
+	f(ctx.doc)
+
+	// Tabs that also toggle aria-expanded label their panel as a tab
+	for panel := range w.tabPanels {
+		delete(w.accordions, panel)
+	}
+	if len(w.tabPanels) == 0 && len(w.accordions) == 0 {
+		return nil
+	}
+	return w
+}
+
+// renderWidget renders tab panels, accordion panels and carousel slides,
+// including collapsed ones, and drops tab strips, carousel clones and controls.
+// It reports whether n was consumed.
+func (ctx *mdContext) renderWidget(n *html.Node) bool {
+	if w := ctx.widgets; w != nil {
+		if w.tablists[n] {
+			return true
+		}
+		if label, ok := w.tabPanels[n]; ok {
+			ctx.renderPanel(n, label)
+			return true
+		}
+		if w.triggers[n] {
+			if label := ctx.accessibleName(n); label != "" {
+				ctx.add(&Title{Text: label})
+			}
+			return true
+		}
+		if w.accordions[n] && !ctx.stripSet[effectiveTag(n)] {
+			ctx.renderPanel(n, "")
+			return true
+		}
+	}
+
+	if hasAnyClass(n, carouselCloneClasses) || hasAnyClass(n, carouselControlClasses) {
+		return true
+	}
+	if isSlide(n) {
+		// Loops without clone classes repeat slides verbatim
+		text := textContent(n)
+		if ctx.slidesSeen == nil {
+			ctx.slidesSeen = make(map[*html.Node]map[string]bool)
+		}
+		seen := ctx.slidesSeen[n.Parent]
+		if seen == nil {
+			seen = make(map[string]bool)
+			ctx.slidesSeen[n.Parent] = seen
+		}
+		if text != "" && !seen[text] {
+			seen[text] = true
+			ctx.renderPanel(n, "")
+		}
+		return true
+	}
+	return false
+}
+
+// isPanel reports whether n is a tab panel, accordion panel or carousel
+// slide: content a widget collapses rather than hides
+func (ctx *mdContext) isPanel(n *html.Node) bool {
+	if w := ctx.widgets; w != nil {
+		if _, ok := w.tabPanels[n]; ok || w.accordions[n] {
+			return true
+		}
+	}
+	return isSlide(n)
+}
+
+func isSlide(n *html.Node) bool {
+	return hasAttr(n, "aria-roledescription", "slide") || hasAnyClass(n, carouselSlideClasses)
+}
+
+// renderPanel writes a panel as its own block, under a sub-heading one level
+// below the current section (bold when there is no room for one)
+func (ctx *mdContext) renderPanel(n *html.Node, label string) {
+	level := ctx.lastHeading
+	defer func() { ctx.lastHeading = level }()
+
+	ctx.text("\n")
+	if label != "" {
+		if level == 0 || level >= ctx.maxHeadingLevel() {
+			ctx.add(&Title{Text: label})
+		} else {
+			ctx.add(&Title{Level: level + 1, Text: label})
+		}
+	}
+	ctx.children(n)
+	ctx.text("\n\n")
+}
+
+// tablistOf returns the tablist containing a tab
+func tablistOf(tab *html.Node) *html.Node {
+	for p := tab.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if hasAttr(p, "role", "tablist") {
+			return p
+		}
+	}
+	return nil
+}
+
+func inHeading(n *html.Node) bool {
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if headingTags[p.Data] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown_Tabs(t *testing.T) {
+	input := []byte(`<html><body>
+		<h2>Install</h2>
+		<div role="tablist">
+			<button role="tab" aria-selected="true" aria-controls="p-npm" id="t-npm">npm</button>
+			<button role="tab" aria-selected="false" aria-controls="p-yarn" id="t-yarn">Yarn</button>
+		</div>
+		<div role="tabpanel" id="p-npm"><pre><code>npm i gremllm</code></pre></div>
+		<div role="tabpanel" id="p-yarn" hidden style="display:none"><pre><code>yarn add gremllm</code></pre></div>
+	</body></html>`)
+
+	for _, cfg := range []StripConfig{{}, {SkipHidden: true}} {
+		result, _ := HTMLToMarkdown(input, cfg)
+		expected := "## Install\n\n### npm\n\n```\nnpm i gremllm\n```\n\n### Yarn\n\n```\nyarn add gremllm\n```"
+		if result != expected {
+			t.Errorf("Expected %q, got: %q", expected, result)
+		}
+	}
+}
+
+func TestHTMLToMarkdown_TabsWithoutHeading(t *testing.T) {
+	input := []byte(`<html><body>
+		<div role="tablist"><span role="tab" id="t1">Specs</span></div>
+		<section role="tabpanel" aria-labelledby="t1" aria-hidden="true"><p>Weight 240g</p></section>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	if result != "**Specs**\n\nWeight 240g" {
+		t.Errorf("Expected bold label when there is no section heading, got: %q", result)
+	}
+}
+
+func TestHTMLToMarkdown_Accordion(t *testing.T) {
+	input := []byte(`<html><body>
+		<h3><button aria-expanded="true" aria-controls="a1">Shipping</button></h3>
+		<div id="a1" role="region"><p>Ships in 2 days.</p></div>
+		<button aria-expanded="false" aria-controls="a2">Returns</button>
+		<div id="a2" hidden><p>30 day returns.</p></div>
+		<button aria-expanded="false" aria-controls="menu" aria-haspopup="true">Account</button>
+		<ul id="menu" role="menu" hidden><li>Sign out</li></ul>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{SkipHidden: true})
+
+	for _, e := range []string{"### Shipping", "Ships in 2 days.", "**Returns**\n\n30 day returns."} {
+		if !strings.Contains(result, e) {
+			t.Errorf("Expected %q, got: %q", e, result)
+		}
+	}
+	if strings.Contains(result, "Sign out") {
+		t.Errorf("Hidden popup menus are not accordion panels, got: %q", result)
+	}
+}
+
+func TestHTMLToMarkdown_Carousel(t *testing.T) {
+	input := []byte(`<html><body>
+		<div class="slick-slider">
+			<button class="slick-prev slick-arrow">Previous</button>
+			<div class="slick-track">
+				<div class="slick-slide slick-cloned" aria-hidden="true"><p>Slide C</p></div>
+				<div class="slick-slide slick-active"><p>Slide A</p></div>
+				<div class="slick-slide" aria-hidden="true"><p>Slide B</p></div>
+				<div class="slick-slide" aria-hidden="true"><p>Slide C</p></div>
+				<div class="slick-slide slick-cloned" aria-hidden="true"><p>Slide A</p></div>
+			</div>
+			<ul class="slick-dots"><li><button>1</button></li></ul>
+		</div>
+		<div class="track">
+			<div aria-roledescription="slide"><p>Loop 1</p></div>
+			<div aria-roledescription="slide"><p>Loop 2</p></div>
+			<div aria-roledescription="slide"><p>Loop 1</p></div>
+		</div>
+	</body></html>`)
+	result, _ := HTMLToMarkdown(input, StripConfig{})
+
+	expected := "Slide A\n\nSlide B\n\nSlide C\n\nLoop 1\n\nLoop 2"
+	if result != expected {
+		t.Errorf("Expected %q, got: %q", expected, result)
+	}
+}
+
+func TestHTMLToMarkdown_PanelsScreened(t *testing.T) {
+	// Collapsing is not hiding, but styling text out of sight still is
+	input := []byte(`<html><body>
+		<div role="tablist"><button role="tab" aria-controls="p1">Overview</button><button role="tab" aria-controls="p2">Details</button></div>
+		<div role="tabpanel" id="p1"><p>Visible tab.</p></div>
+		<div role="tabpanel" id="p2" style="position:absolute;left:-9999px"><p>Off-screen tab.</p></div>
+		<div class="swiper"><div class="swiper-slide"><p>Slide one.</p></div><div class="swiper-slide" style="font-size:0"><p>Tiny slide.</p></div></div>
+	</body></html>`)
+	result, err := Convert(input, StripConfig{Defense: DefenseStrip})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if strings.Contains(result.Markdown, "Off-screen") || strings.Contains(result.Markdown, "Tiny") {
+		t.Errorf("Expected concealed panels stripped, got: %q", result.Markdown)
+	}
+	if !strings.Contains(result.Markdown, "Visible tab.") || !strings.Contains(result.Markdown, "Slide one.") {
+		t.Errorf("Expected visible panels kept, got: %q", result.Markdown)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected two hidden-text warnings, got: %v", result.Warnings)
+	}
+}
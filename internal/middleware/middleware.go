@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,9 +23,34 @@ const (
 	cacheTTL     = 5 * time.Minute
 )
 
+// Warnings beyond this count are summarised in the header as "+N more"
+const maxHeaderWarnings = 10
+
+// Markdown of pages larger than this is written as it is converted, and
+// cached once the stream is complete
+const streamThreshold = 1 << 20
+
+// Conversion settings used for every response. Agents read /page.md directly,
+// so hidden text and injected instructions are marked and reported. Tables
+// keep every row in markdown unless the request asks otherwise.
+var convertConfig = converter.StripConfig{
+	Defense: converter.DefenseFlag,
+}
+
+// Table formats a request can ask for with ?gremllm&tables=
+var tableFormats = map[string]converter.TableFormat{
+	"markdown": converter.TablesMarkdown,
+	"csv":      converter.TablesCSV,
+	"tsv":      converter.TablesTSV,
+	"tonl":     converter.TablesTONL,
+	"auto":     converter.TablesAuto,
+}
+
 // Cache for converted markdown
 type cacheEntry struct {
 	content   string
+	warnings  []converter.Warning
+	status    int // 404 for a section or selector that matched nothing
 	timestamp time.Time
 }
 
@@ -77,11 +107,21 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 
 // GremllmMiddleware wraps an existing http.Handler to support ?gremllm query parameter.
 // When ?gremllm is present in the URL, captures the response, processes the HTML,
-// and returns the cleaned markdown version.
+// and returns the cleaned markdown version. ?gremllm&section=id returns only the
+// section with that id and ?gremllm&select=selector only the elements matching
+// a CSS selector. When nothing matches the response is a 404 whose body is the
+// page's table of contents. ?gremllm=outline returns just that table of
+// contents, with a token estimate per section. &tables=csv, tsv, tonl or auto
+// writes data tables in a compact format, and &rows=n keeps the first n rows
+// of each table and counts the rest. ?gremllm=json, or a request
+// whose Accept header ranks application/json above text/markdown, returns the
+// document as versioned JSON blocks (see converter.JSONDocument). Markdown of pages over streamThreshold
+// is written as it is converted.
 func GremllmMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if ?gremllm query parameter is present
-		_, hasGremllm := r.URL.Query()["gremllm"]
+		query := r.URL.Query()
+		_, hasGremllm := query["gremllm"]
 
 		if hasGremllm {
 			// Capture the response
@@ -108,46 +148,109 @@ func GremllmMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
-			// Check cache first
+			cfg := convertConfig
+			cfg.Section = query.Get("section")
+			cfg.Select = query.Get("select")
+			if name := query.Get("tables"); name != "" {
+				format, ok := tableFormats[name]
+				if !ok {
+					http.Error(w, "unknown table format "+strconv.Quote(name), http.StatusBadRequest)
+					return
+				}
+				cfg.Tables.Format = format
+			}
+			if rows := query.Get("rows"); rows != "" {
+				n, err := strconv.Atoi(rows)
+				if err != nil || n <= 0 {
+					http.Error(w, "rows must be a positive number", http.StatusBadRequest)
+					return
+				}
+				cfg.Tables.MaxRows = n
+			}
+			mode := query.Get("gremllm")
+			if mode == "outline" {
+				cfg.Outline = converter.OutlineOnly
+			}
+			asJSON := mode == "json" || (mode != "outline" && acceptsJSON(r))
+
 			htmlBytes := rw.body.Bytes()
+
+			// Check cache first
 			cacheKey := hashContent(htmlBytes)
+			if cfg.Section != "" || cfg.Select != "" {
+				cacheKey += "#" + cfg.Section + "?" + cfg.Select
+			}
+			if cfg.Outline != converter.OutlineNone {
+				cacheKey += "!outline"
+			}
+			if cfg.Tables != convertConfig.Tables {
+				cacheKey += "!tables=" + strconv.Itoa(int(cfg.Tables.Format)) + "," + strconv.Itoa(cfg.Tables.MaxRows)
+			}
+			if asJSON {
+				cacheKey += "!json"
+			}
 
 			cacheMu.RLock()
 			entry, found := cache[cacheKey]
 			cacheMu.RUnlock()
+			found = found && time.Since(entry.timestamp) < cacheTTL
 
-			var markdown string
-			if found && time.Since(entry.timestamp) < cacheTTL {
-				markdown = entry.content
+			if !found && len(htmlBytes) > streamThreshold && !asJSON && cfg.Outline == converter.OutlineNone &&
+				cfg.Section == "" && cfg.Select == "" {
+				streamMarkdown(w, htmlBytes, cfg, cacheKey)
+				return
+			}
+
+			var content string
+			var warnings []converter.Warning
+			status := http.StatusOK
+			if found {
+				content = entry.content
+				warnings = entry.warnings
+				status = entry.status
 			} else {
 				// Convert HTML to markdown
-				var err error
-				markdown, err = converter.HTMLToMarkdown(htmlBytes, converter.StripConfig{})
-				if err != nil {
+				result, err := converter.Convert(htmlBytes, cfg)
+				switch {
+				case errors.Is(err, converter.ErrSectionNotFound):
+					// Cached like a page, so repeating the request costs no conversion
+					status = http.StatusNotFound
+					content = notFoundBody(cfg, result.Outline, asJSON)
+				case errors.Is(err, converter.ErrInvalidSelector):
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				case err != nil:
 					http.Error(w, err.Error(), http.StatusInternalServerError)
 					return
+				default:
+					content = result.Markdown
+					warnings = result.Warnings
 				}
-
-				// Cache the result
-				cacheMu.Lock()
-				// Check if we need to evict
-				if len(cache) >= maxCacheSize {
-					// Evict oldest entry
-					evictOldest(1)
+				if asJSON && status == http.StatusOK {
+					body, err := json.Marshal(converter.NewJSONDocument(result, cfg))
+					if err != nil {
+						http.Error(w, err.Error(), http.StatusInternalServerError)
+						return
+					}
+					content = string(body)
 				}
 
-				// Add new entry
-				if _, exists := cache[cacheKey]; !exists {
-					cacheOrder = append(cacheOrder, cacheKey)
-				}
-				cache[cacheKey] = cacheEntry{content: markdown, timestamp: time.Now()}
-				cacheMu.Unlock()
+				// Cache the result
+				storeCache(cacheKey, cacheEntry{content: content, warnings: warnings, status: status})
 			}
 
-			// Return the processed markdown
-			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
-			w.WriteHeader(rw.statusCode)
-			w.Write([]byte(markdown))
+			// Return the converted content
+			if asJSON {
+				w.Header().Set("Content-Type", "application/json")
+			} else {
+				w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			}
+			w.Header().Set("Vary", "Accept")
+			if len(warnings) > 0 {
+				w.Header().Set("X-Gremllm-Warnings", formatWarnings(warnings))
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(content))
 		} else {
 			// No ?gremllm parameter, just pass through
 			next.ServeHTTP(w, r)
@@ -155,6 +258,133 @@ func GremllmMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// storeCache adds an entry to the cache, evicting the oldest when it is full
+func storeCache(key string, entry cacheEntry) {
+	entry.timestamp = time.Now()
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	// Check if we need to evict
+	if len(cache) >= maxCacheSize {
+		// Evict oldest entry
+		evictOldest(1)
+	}
+
+	// Add new entry
+	if _, exists := cache[key]; !exists {
+		cacheOrder = append(cacheOrder, key)
+	}
+	cache[key] = entry
+}
+
+// streamMarkdown writes the markdown of a large page as it is converted, so
+// the page is never held as a document tree. The HTML itself is in memory
+// already, captured from the handler, and ConvertStream buffers each
+// top-level element whole (see converter.ConvertStream): a page that is one
+// <table> costs as much as converting it outright. The warnings are only
+// known at the end and are sent as a trailer. A complete stream is cached
+// under key, so the next request for the page is served from memory.
+func streamMarkdown(w http.ResponseWriter, htmlBytes []byte, cfg converter.StripConfig, key string) {
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Trailer", "X-Gremllm-Warnings")
+	w.WriteHeader(http.StatusOK)
+
+	// The status is sent, so a failure can only cut the body short
+	var content strings.Builder
+	warnings, err := converter.ConvertStream(bytes.NewReader(htmlBytes), io.MultiWriter(w, &content), cfg)
+	if err != nil {
+		return
+	}
+	if len(warnings) > 0 {
+		w.Header().Set("X-Gremllm-Warnings", formatWarnings(warnings))
+	}
+	storeCache(key, cacheEntry{content: content.String(), warnings: warnings, status: http.StatusOK})
+}
+
+// notFoundJSON is the 404 body of a JSON request
+type notFoundJSON struct {
+	Error   string         `json:"error"`
+	Outline []outlineEntry `json:"outline"`
+}
+
+type outlineEntry struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor,omitempty"`
+	Tokens int    `json:"tokens"`
+}
+
+// notFoundBody answers a section or selector that matched nothing with the
+// page's table of contents, so the agent can pick a section that exists
+func notFoundBody(cfg converter.StripConfig, outline []converter.OutlineEntry, asJSON bool) string {
+	wanted := "Section " + strconv.Quote(cfg.Section)
+	if cfg.Section == "" {
+		wanted = "Selector " + strconv.Quote(cfg.Select)
+	}
+
+	if asJSON {
+		body := notFoundJSON{Error: wanted + " not found.", Outline: []outlineEntry{}}
+		for _, e := range outline {
+			body.Outline = append(body.Outline, outlineEntry{Level: e.Level, Text: e.Text, Anchor: e.ID, Tokens: e.Tokens})
+		}
+		encoded, _ := json.Marshal(body) // Strings and ints only, it cannot fail
+		return string(encoded)
+	}
+
+	var body strings.Builder
+	body.WriteString(wanted + " not found.\n")
+	if len(outline) > 0 {
+		body.WriteString("\n## Contents\n\n")
+		body.WriteString(converter.FormatTOC(outline))
+	}
+	return body.String()
+}
+
+// acceptsJSON reports whether the request's Accept header prefers
+// application/json to markdown: JSON must rank above text/markdown by q-value,
+// so "*/*" or an equal ranking keeps markdown
+func acceptsJSON(r *http.Request) bool {
+	q := acceptQuality(r, "application/json")
+	return q > 0 && q > acceptQuality(r, "text/markdown")
+}
+
+// acceptQuality returns the q-value the Accept header gives a media type, from
+// the most specific range that matches it: type/subtype, type/* or */*
+func acceptQuality(r *http.Request, media string) float64 {
+	major, _, _ := strings.Cut(media, "/")
+	quality, specificity := 0.0, -1
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			t, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+			var s int
+			switch t {
+			case media:
+				s = 2
+			case major + "/*":
+				s = 1
+			case "*/*":
+				s = 0
+			default:
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					q = 0
+				}
+			}
+			if s > specificity || (s == specificity && q > quality) {
+				quality, specificity = q, s
+			}
+		}
+	}
+	return quality
+}
+
 // copyHeaders copies headers from src to dst
 func copyHeaders(dst, src http.Header) {
 	for k, v := range src {
@@ -162,6 +392,20 @@ func copyHeaders(dst, src http.Header) {
 	}
 }
 
+// formatWarnings summarises conversion warnings for the X-Gremllm-Warnings header.
+// Only the kind and reason are included; the offending page text never is.
+func formatWarnings(warnings []converter.Warning) string {
+	parts := make([]string, 0, min(len(warnings), maxHeaderWarnings)+1)
+	for i, w := range warnings {
+		if i == maxHeaderWarnings {
+			parts = append(parts, "+"+strconv.Itoa(len(warnings)-i)+" more")
+			break
+		}
+		parts = append(parts, w.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
 // hashContent creates a cache key from content
 func hashContent(content []byte) string {
 	h := md5.Sum(content)